@@ -0,0 +1,102 @@
+package gormqs
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+Sum column of any Querier, empty set return zero value
+
+	total, err := gormqs.Sum[float64](ctx, userQueries, "balance", Where("balance > ?", 0))
+	// SQL: SELECT SUM(`balance`) FROM `users` WHERE balance > 0
+*/
+func Sum[T any](ctx context.Context, q Querier, column string, opts ...Option) (T, error) {
+	result, err := aggregate[T](ctx, q, "SUM", column, opts)
+	return result.V, err
+}
+
+/*
+Avg column of any Querier, empty set return invalid sql.Null
+
+	avg, err := gormqs.Avg[float64](ctx, userQueries, "balance")
+	if avg.Valid {
+		log.Println(avg.V)
+	}
+*/
+func Avg[T any](ctx context.Context, q Querier, column string, opts ...Option) (sql.Null[T], error) {
+	return aggregate[T](ctx, q, "AVG", column, opts)
+}
+
+// Min column of any Querier, empty set return invalid sql.Null
+func Min[T any](ctx context.Context, q Querier, column string, opts ...Option) (sql.Null[T], error) {
+	return aggregate[T](ctx, q, "MIN", column, opts)
+}
+
+// Max column of any Querier, empty set return invalid sql.Null
+func Max[T any](ctx context.Context, q Querier, column string, opts ...Option) (sql.Null[T], error) {
+	return aggregate[T](ctx, q, "MAX", column, opts)
+}
+
+/*
+Exists report whether any row match
+
+	exists, err := gormqs.Exists(ctx, userQueries, Where("username = ?", "foxie"))
+	// SQL: SELECT 1 FROM `users` WHERE username = "foxie" LIMIT 1
+*/
+func Exists(ctx context.Context, q Querier, opts ...Option) (bool, error) {
+	tx := readInstance(ctx, q, opts).Select("1").Limit(1)
+	rows, err := tx.Rows()
+	if isDryRunErr(tx, err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, translateError(err)
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+	return exists, translateError(rows.Err())
+}
+
+/*
+Pluck single column into typed slice
+
+	names, err := gormqs.Pluck[string](ctx, userQueries, "username", Where("balance > ?", 0))
+	// SQL: SELECT `username` FROM `users` WHERE balance > 0
+*/
+func Pluck[T any](ctx context.Context, q Querier, column string, opts ...Option) ([]T, error) {
+	var result []T
+	err := readInstance(ctx, q, opts).Pluck(column, &result).Error
+	return result, translateError(err)
+}
+
+func aggregate[T any](ctx context.Context, q Querier, fn string, column string, opts []Option) (sql.Null[T], error) {
+	var result sql.Null[T]
+	tx := readInstance(ctx, q, opts).Select(fn+"(?)", clause.Column{Name: column})
+	err := scanRow(tx, &result)
+	return result, translateError(err)
+}
+
+// scanRow scan first row into dest, dest is untouched when there is no row
+func scanRow(tx *gorm.DB, dest ...any) error {
+	rows, err := tx.Rows()
+	if isDryRunErr(tx, err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
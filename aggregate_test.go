@@ -0,0 +1,96 @@
+package gormqs_test
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+)
+
+func TestAggregate(t *testing.T) {
+	// balances 1 to 4
+	qs := newSqliteQueries(t, seedUsers(4)...)
+	ctx := context.Background()
+
+	sum, err := gormqs.Sum[float64](ctx, qs, "balance")
+	if err != nil || sum != 10 {
+		t.Errorf("sum got %v, %v, want 10", sum, err)
+	}
+
+	avg, err := gormqs.Avg[float64](ctx, qs, "balance")
+	if err != nil || !avg.Valid || avg.V != 2.5 {
+		t.Errorf("avg got %v, %v, want 2.5", avg, err)
+	}
+
+	minimum, err := gormqs.Min[float64](ctx, qs, "balance", gormqs.Where("balance > ?", 1))
+	if err != nil || !minimum.Valid || minimum.V != 2 {
+		t.Errorf("min got %v, %v, want 2", minimum, err)
+	}
+
+	maximum, err := gormqs.Max[float64](ctx, qs, "balance")
+	if err != nil || !maximum.Valid || maximum.V != 4 {
+		t.Errorf("max got %v, %v, want 4", maximum, err)
+	}
+
+	t.Run("empty set", func(t *testing.T) {
+		empty := gormqs.Where("balance > ?", 100)
+
+		sum, err := gormqs.Sum[float64](ctx, qs, "balance", empty)
+		if err != nil || sum != 0 {
+			t.Errorf("sum got %v, %v, want 0", sum, err)
+		}
+
+		for name, aggregate := range map[string]func(context.Context, gormqs.Querier, string, ...gormqs.Option) (sql.Null[float64], error){
+			"avg": gormqs.Avg[float64],
+			"min": gormqs.Min[float64],
+			"max": gormqs.Max[float64],
+		} {
+			result, err := aggregate(ctx, qs, "balance", empty)
+			if err != nil || result.Valid {
+				t.Errorf("%s got %v, %v, want invalid null", name, result, err)
+			}
+		}
+	})
+}
+
+func TestExists(t *testing.T) {
+	qs := newSqliteQueries(t, seedUsers(2)...)
+	ctx := context.Background()
+
+	if exists, err := gormqs.Exists(ctx, qs, gormqs.Where("username = ?", "a")); err != nil || !exists {
+		t.Errorf("got %v, %v, want true", exists, err)
+	}
+	if exists, err := gormqs.Exists(ctx, qs, gormqs.Where("username = ?", "z")); err != nil || exists {
+		t.Errorf("got %v, %v, want false", exists, err)
+	}
+}
+
+func TestPluck(t *testing.T) {
+	qs := newSqliteQueries(t, seedUsers(4)...)
+	ctx := context.Background()
+
+	names, err := gormqs.Pluck[string](ctx, qs, "username", gormqs.Where("balance > ?", 1), gormqs.OrderBy(gormqs.Desc("id")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"d", "c", "b"}) {
+		t.Errorf("got %v, want [d c b]", names)
+	}
+
+	// option used as a scope
+	richest := func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(func(db *gorm.DB) *gorm.DB {
+			return db.Where("balance >= ?", 4)
+		})
+	}
+	ids, err := gormqs.Pluck[uint](ctx, qs, "id", richest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, []uint{4}) {
+		t.Errorf("got %v, want [4]", ids)
+	}
+}
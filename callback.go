@@ -0,0 +1,230 @@
+package gormqs
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+/*
+OnCommit run fn after the outermost transaction of ctx is committed, at once when ctx has no transaction.
+callbacks run in registration order with ctx of the outermost transaction, which no longer carries the tx
+
+	err := gormqs.Transaction(ctx, db, func(ctx context.Context) error {
+		if err := orderQueries.CreateOne(ctx, order); err != nil {
+			return err
+		}
+
+		gormqs.OnCommit(ctx, func(ctx context.Context) {
+			mailer.SendOrderCreated(ctx, order.ID)
+		})
+		return nil
+	})
+
+ctx of WrapContext bind callbacks to tx of gorm db.Transaction or db.Begin, they run when that tx is committed
+or rolled back. savepoints of nested gorm db.Transaction are not tracked, callbacks registered in a rolled back
+one still run on commit, nest with Transaction instead.
+when ctx carries transactions of many named databases, callbacks bind to the latest one.
+a panic of callback is raised again after the other callbacks have run
+*/
+func OnCommit(ctx context.Context, fn func(ctx context.Context)) {
+	callbacks := ContextValue[*txCallbacks](ctx, nil)
+	if callbacks == nil {
+		fn(ctx)
+		return
+	}
+	callbacks.register(true, fn)
+}
+
+/*
+OnRollback run fn after the outermost transaction of ctx is rolled back, never when ctx has no transaction.
+fn registered in a savepoint of Transaction which is rolled back runs when the outermost transaction
+finishes, even when it is committed
+*/
+func OnRollback(ctx context.Context, fn func(ctx context.Context)) {
+	callbacks := ContextValue[*txCallbacks](ctx, nil)
+	if callbacks == nil {
+		return
+	}
+	callbacks.register(false, fn)
+}
+
+// txCallbacks callbacks of one transaction, run once by commit or rollback of callbackTx
+type txCallbacks struct {
+	mu         sync.Mutex
+	ctx        context.Context
+	done       bool
+	committed  bool
+	onCommit   []func(ctx context.Context)
+	onRollback []rollbackCallback
+}
+
+type rollbackCallback struct {
+	fn func(ctx context.Context)
+
+	// savepoint of fn is rolled back, fn runs on commit too
+	discarded bool
+}
+
+// register fn, or run it at once when the transaction is already finished with the same outcome
+func (c *txCallbacks) register(commit bool, fn func(ctx context.Context)) {
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		if c.committed == commit {
+			fn(c.ctx)
+		}
+		return
+	}
+
+	if commit {
+		c.onCommit = append(c.onCommit, fn)
+	} else {
+		c.onRollback = append(c.onRollback, rollbackCallback{fn: fn})
+	}
+	c.mu.Unlock()
+}
+
+// finish run OnRollback callbacks of rolled back savepoints then OnCommit ones on commit, every OnRollback one otherwise
+func (c *txCallbacks) finish(committed bool) {
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		return
+	}
+	c.done, c.committed = true, committed
+
+	var fns []func(ctx context.Context)
+	for _, callback := range c.onRollback {
+		if !committed || callback.discarded {
+			fns = append(fns, callback.fn)
+		}
+	}
+	if committed {
+		fns = append(fns, c.onCommit...)
+	}
+	c.onCommit, c.onRollback = nil, nil
+	c.mu.Unlock()
+
+	runCallbacks(c.ctx, fns)
+}
+
+// runCallbacks run every fn, the first panic is raised again after all of them have run
+func runCallbacks(ctx context.Context, fns []func(ctx context.Context)) {
+	var (
+		panicked  bool
+		recovered any
+	)
+	for _, fn := range fns {
+		func() {
+			defer func() {
+				if r := recover(); r != nil && !panicked {
+					panicked, recovered = true, r
+				}
+			}()
+			fn(ctx)
+		}()
+	}
+
+	if panicked {
+		panic(recovered)
+	}
+}
+
+// savepoint position of registered callbacks
+type savepoint struct {
+	commit, rollback int
+}
+
+func (c *txCallbacks) savepoint() savepoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return savepoint{commit: len(c.onCommit), rollback: len(c.onRollback)}
+}
+
+// rollbackTo drop OnCommit callbacks registered after sp, OnRollback ones wait for the outermost transaction
+func (c *txCallbacks) rollbackTo(sp savepoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done {
+		return
+	}
+
+	for i := sp.rollback; i < len(c.onRollback); i++ {
+		c.onRollback[i].discarded = true
+	}
+	if sp.commit < len(c.onCommit) {
+		c.onCommit = c.onCommit[:sp.commit]
+	}
+}
+
+// callbackTx tx conn pool running callbacks after commit or rollback
+type callbackTx struct {
+	gorm.ConnPool
+	callbacks *txCallbacks
+}
+
+func (tx *callbackTx) Commit() error {
+	if err := tx.ConnPool.(gorm.TxCommitter).Commit(); err != nil {
+		tx.callbacks.finish(false)
+		return err
+	}
+	tx.callbacks.finish(true)
+	return nil
+}
+
+func (tx *callbackTx) Rollback() error {
+	err := tx.ConnPool.(gorm.TxCommitter).Rollback()
+	tx.callbacks.finish(false)
+	return err
+}
+
+// StmtContext keep prepared statements bound to the tx, see gorm.Tx
+func (tx *callbackTx) StmtContext(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	if stmtTx, ok := tx.ConnPool.(interface {
+		StmtContext(context.Context, *sql.Stmt) *sql.Stmt
+	}); ok {
+		return stmtTx.StmtContext(ctx, stmt)
+	}
+	return stmt
+}
+
+// GetDBConn keep gorm.DB.DB working inside transaction
+func (tx *callbackTx) GetDBConn() (*sql.DB, error) {
+	return (&gorm.DB{Config: &gorm.Config{ConnPool: tx.ConnPool}}).DB()
+}
+
+// withCallbacks bind callbacks of tx into ctx, conn pool of tx is wrapped when it is a new transaction
+func withCallbacks(ctx context.Context, tx *gorm.DB) context.Context {
+	callbacks := poolCallbacks(tx.Statement.ConnPool)
+	if callbacks == nil {
+		switch pool := tx.Statement.ConnPool.(type) {
+		case *gorm.PreparedStmtTX:
+			// gorm unwrap prepared stmt tx for savepoints, wrap the inner tx instead
+			callbacks = &txCallbacks{ctx: ctx}
+			pool.Tx = &callbackTx{ConnPool: pool.Tx, callbacks: callbacks}
+		case gorm.TxCommitter:
+			callbacks = &txCallbacks{ctx: ctx}
+			tx.Statement.ConnPool = &callbackTx{ConnPool: tx.Statement.ConnPool, callbacks: callbacks}
+		default:
+			return ctx
+		}
+	}
+
+	return ContextWithValue(ctx, callbacks)
+}
+
+// poolCallbacks callbacks of transaction conn pool wrapped by withCallbacks
+func poolCallbacks(pool gorm.ConnPool) *txCallbacks {
+	switch pool := pool.(type) {
+	case *callbackTx:
+		return pool.callbacks
+	case *gorm.PreparedStmtTX:
+		if inner, ok := pool.Tx.(*callbackTx); ok {
+			return inner.callbacks
+		}
+	}
+	return nil
+}
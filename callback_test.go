@@ -0,0 +1,99 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+)
+
+func TestOnCommit(t *testing.T) {
+	events := new([]string)
+	db, err := gorm.Open(savepointDialector{events: events}, &gorm.Config{ConnPool: txRecorder{events: events}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(event string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			if gormqs.ContextValue[*gorm.DB](ctx, nil) != nil {
+				t.Errorf("%s: ctx of callback carries the tx", event)
+			}
+			*events = append(*events, event)
+		}
+	}
+
+	errNested := errors.New("nested")
+	err = gormqs.Transaction(context.Background(), db, func(ctx context.Context) error {
+		gormqs.OnCommit(ctx, record("commit 1"))
+
+		_ = gormqs.Transaction(ctx, db, func(ctx context.Context) error {
+			gormqs.OnCommit(ctx, record("dropped"))
+			gormqs.OnRollback(ctx, record("savepoint rolled back"))
+			return errNested
+		})
+
+		gormqs.OnCommit(ctx, record("commit 2"))
+		gormqs.OnRollback(ctx, record("never"))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// OnRollback of the rolled back savepoint wait for the outermost transaction
+	expected := []string{"begin Default readonly=false", "savepoint", "rollback to", "commit", "savepoint rolled back", "commit 1", "commit 2"}
+	if !reflect.DeepEqual(*events, expected) {
+		t.Errorf("expected %v, got %v", expected, *events)
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		*events = nil
+		_ = db.Transaction(func(tx *gorm.DB) error {
+			ctx := gormqs.WrapContext(tx)
+			gormqs.OnCommit(ctx, record("never"))
+			gormqs.OnRollback(ctx, record("rolled back"))
+			return errNested
+		})
+
+		expected := []string{"begin Default readonly=false", "rollback", "rolled back"}
+		if !reflect.DeepEqual(*events, expected) {
+			t.Errorf("expected %v, got %v", expected, *events)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		*events = nil
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("got panic %v, want boom", r)
+			}
+
+			expected := []string{"begin Default readonly=false", "commit", "commit 2"}
+			if !reflect.DeepEqual(*events, expected) {
+				t.Errorf("expected %v, got %v", expected, *events)
+			}
+		}()
+
+		_ = gormqs.Transaction(context.Background(), db, func(ctx context.Context) error {
+			gormqs.OnCommit(ctx, func(ctx context.Context) { panic("boom") })
+			gormqs.OnCommit(ctx, record("commit 2"))
+			return nil
+		})
+	})
+
+	t.Run("without transaction", func(t *testing.T) {
+		*events = nil
+		ctx := context.Background()
+		gormqs.OnCommit(ctx, record("at once"))
+		gormqs.OnRollback(ctx, record("never"))
+
+		expected := []string{"at once"}
+		if !reflect.DeepEqual(*events, expected) {
+			t.Errorf("expected %v, got %v", expected, *events)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+const header = "// Code generated by gormqs-gen. DO NOT EDIT."
+
+// reserved method names of generated schema
+var reserved = []string{"Where", "Select", "SelectAll", "WhereID"}
+
+var schemaTemplate = template.Must(template.New("schema").Parse(header + `
+
+package {{ .Package }}
+
+import (
+	"fmt"
+{{ range .Imports }}
+	{{ . }}
+{{- end }}
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+)
+
+type {{ .Model.Name }}Column string
+
+type {{ .Model.Name }}Schema struct {
+{{- range .Model.Columns }}
+	{{ .Field }} {{ $.Model.Name }}Column
+{{- end }}
+}
+
+var {{ .Var }} = {{ .Model.Name }}Schema{
+{{- range .Model.Columns }}
+	{{ .Field }}: "{{ .Name }}",
+{{- end }}
+}
+
+func (s {{ .Model.Name }}Schema) Where(col {{ .Model.Name }}Column, operation, value any) gormqs.Option {
+	return func(db *gorm.DB) *gorm.DB {
+		query := fmt.Sprintf("%s %s ?", gormqs.WithTable(string(col), db), operation)
+		return db.Where(query, value)
+	}
+}
+
+func (s {{ .Model.Name }}Schema) SelectAll(db *gorm.DB) *gorm.DB {
+	return db.Select("*")
+}
+
+func (s {{ .Model.Name }}Schema) Select(cols ...{{ .Model.Name }}Column) gormqs.Option {
+	return func(db *gorm.DB) *gorm.DB {
+		columns := make([]string, len(cols))
+		for i, col := range cols {
+			columns[i] = gormqs.WithTable(string(col), db)
+		}
+		return db.Select(columns)
+	}
+}
+{{- with .Model.PrimaryKey }}
+
+func (s {{ $.Model.Name }}Schema) WhereID(id {{ .Type }}) gormqs.Option {
+	return s.Where(s.{{ .Field }}, "=", id)
+}
+{{- end }}
+{{- range .Model.Relations }}
+
+func (s {{ $.Model.Name }}Schema) Preload{{ . }}(args ...any) gormqs.Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload("{{ . }}", args...)
+	}
+}
+{{- end }}
+`))
+
+// fileName of generated schema, e.g. order_item_gen.go
+func fileName(m *model) string {
+	return naming.ColumnName("", m.Name) + "_gen.go"
+}
+
+// generate formatted schema file of model
+func generate(pkg string, m *model) ([]byte, error) {
+	for _, col := range m.Columns {
+		if slices.Contains(reserved, col.Field) {
+			return nil, fmt.Errorf("%s: field %s conflict with generated method", m.Name, col.Field)
+		}
+	}
+	for _, relation := range m.Relations {
+		if slices.Contains(reserved, relation) {
+			return nil, fmt.Errorf("%s: field %s conflict with generated method", m.Name, relation)
+		}
+	}
+
+	imports := slices.Clone(m.Imports)
+	slices.Sort(imports)
+	imports = slices.Compact(imports)
+
+	var buf bytes.Buffer
+	err := schemaTemplate.Execute(&buf, map[string]any{
+		"Package": pkg,
+		"Imports": imports,
+		"Model":   m,
+		"Var":     strings.ToUpper(naming.ColumnName("", m.Name)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
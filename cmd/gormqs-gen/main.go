@@ -0,0 +1,141 @@
+/*
+gormqs-gen generate typed schema of gorm models for gormqs
+
+	go run github.com/foxie-io/gormqs/cmd/gormqs-gen -models ./models -out ./queries/options -pkg qopt
+
+every struct with TableName method is a model, one <model>_gen.go is written per model.
+use -check in CI to fail when generated files are stale
+
+	//go:generate go run github.com/foxie-io/gormqs/cmd/gormqs-gen -models ../../models -out .
+*/
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func main() {
+	var (
+		modelsDir = flag.String("models", ".", "directory of model package")
+		outDir    = flag.String("out", ".", "output directory of generated files")
+		pkg       = flag.String("pkg", "", "package name of generated files, default to base name of out")
+		check     = flag.Bool("check", false, "report stale generated files without writing")
+	)
+	flag.Parse()
+
+	if err := run(*modelsDir, *outDir, *pkg, *check); err != nil {
+		fmt.Fprintln(os.Stderr, "gormqs-gen:", err)
+		os.Exit(1)
+	}
+}
+
+var errStale = errors.New("generated files are stale, run gormqs-gen")
+
+func run(modelsDir, outDir, pkg string, check bool) error {
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = filepath.Base(absOut)
+	}
+
+	absModels, err := filepath.Abs(modelsDir)
+	if err != nil {
+		return err
+	}
+
+	// schema generated into the models package use local types as is
+	models, err := parseModels(modelsDir, absModels != absOut)
+	if err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("no model found in %s", modelsDir)
+	}
+
+	files := map[string][]byte{}
+	for _, m := range models {
+		content, err := generate(pkg, m)
+		if err != nil {
+			return err
+		}
+		files[filepath.Join(outDir, fileName(m))] = content
+	}
+
+	obsolete, err := obsoleteFiles(outDir, files)
+	if err != nil {
+		return err
+	}
+
+	if check {
+		return checkFiles(files, obsolete)
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+	}
+	for _, path := range obsolete {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// obsoleteFiles generated files of removed models
+func obsoleteFiles(outDir string, files map[string][]byte) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(outDir, "*_gen.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	var obsolete []string
+	for _, path := range paths {
+		if _, ok := files[path]; ok {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(content, []byte(header)) {
+			obsolete = append(obsolete, path)
+		}
+	}
+	return obsolete, nil
+}
+
+func checkFiles(files map[string][]byte, obsolete []string) error {
+	var stale []string
+	for path, content := range files {
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if !bytes.Equal(existing, content) {
+			stale = append(stale, path)
+		}
+	}
+	stale = append(stale, obsolete...)
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	slices.Sort(stale)
+	return fmt.Errorf("%w:\n\t%s", errStale, strings.Join(stale, "\n\t"))
+}
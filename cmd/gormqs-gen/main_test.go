@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testModels = `package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Base struct {
+	ID        uuid.UUID ` + "`gorm:\"primaryKey\"`" + `
+	CreatedAt time.Time
+}
+
+type Account struct {
+	Base
+	Email    string ` + "`gorm:\"column:email_address\"`" + `
+	Password string ` + "`gorm:\"-\"`" + `
+	Address  Address ` + "`gorm:\"embedded;embeddedPrefix:addr_\"`" + `
+	Posts    []*Post
+}
+
+func (Account) TableName() string { return "accounts" }
+
+type Address struct {
+	City string
+}
+
+type Post struct {
+	gorm.Model
+	AccountID uuid.UUID
+}
+
+func (*Post) TableName() string { return "posts" }
+
+type TagID uint
+
+type Meta struct {
+	Color string
+}
+
+type Extra struct {
+	TagID TagID
+}
+
+type Tag struct {
+	ID       TagID ` + "`gorm:\"primaryKey\"`" + `
+	Meta     Meta  ` + "`gorm:\"serializer:json\"`" + `
+	Settings Meta
+	Extra    Extra ` + "`gorm:\"foreignKey:TagID\"`" + `
+	Posts    []Post
+}
+
+func (Tag) TableName() string { return "tags" }
+`
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	modelsDir, outDir := filepath.Join(dir, "models"), filepath.Join(dir, "qopt")
+	if err := os.MkdirAll(modelsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(modelsDir, "models.go"), []byte(testModels), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(modelsDir, outDir, "", true); !errors.Is(err, errStale) {
+		t.Fatalf("check before generate got %v, want errStale", err)
+	}
+
+	if err := run(modelsDir, outDir, "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := os.ReadFile(filepath.Join(outDir, "account_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"package qopt",
+		`"github.com/google/uuid"`,
+		`ID: "id",`,
+		`Email: "email_address",`,
+		`City: "addr_city",`,
+		"func (s AccountSchema) WhereID(id uuid.UUID) gormqs.Option {",
+		"func (s AccountSchema) PreloadPosts(args ...any) gormqs.Option {",
+	} {
+		if !strings.Contains(collapse(account), expected) {
+			t.Errorf("account_gen.go missing %s", expected)
+		}
+	}
+	if strings.Contains(string(account), "Password") {
+		t.Error("ignored field must not be generated")
+	}
+
+	post, err := os.ReadFile(filepath.Join(outDir, "post_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(collapse(post), `DeletedAt: "deleted_at",`) {
+		t.Error("post_gen.go missing columns of gorm.Model")
+	}
+
+	// struct columns stay columns, local types are qualified by the models package
+	tag, err := os.ReadFile(filepath.Join(outDir, "tag_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`"example.com/app/models"`,
+		`Meta: "meta",`,
+		`Settings: "settings",`,
+		"func (s TagSchema) WhereID(id models.TagID) gormqs.Option {",
+		"func (s TagSchema) PreloadExtra(args ...any) gormqs.Option {",
+		"func (s TagSchema) PreloadPosts(args ...any) gormqs.Option {",
+	} {
+		if !strings.Contains(collapse(tag), expected) {
+			t.Errorf("tag_gen.go missing %s", expected)
+		}
+	}
+	if strings.Contains(string(tag), "PreloadMeta") || strings.Contains(string(tag), "PreloadSettings") {
+		t.Error("struct columns must not be relations")
+	}
+
+	if err := run(modelsDir, outDir, "", true); err != nil {
+		t.Fatalf("check after generate got %v", err)
+	}
+}
+
+// collapse whitespace of gofmt alignment
+func collapse(content []byte) string {
+	return strings.Join(strings.Fields(string(content)), " ")
+}
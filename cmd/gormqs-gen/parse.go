@@ -0,0 +1,452 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+var naming = schema.NamingStrategy{}
+
+// model parsed from a struct with TableName method
+type model struct {
+	Name       string
+	Columns    []column
+	Relations  []string
+	PrimaryKey *column // nil for composite or missing primary key
+	Imports    []string
+}
+
+type column struct {
+	Field   string
+	Name    string
+	Type    string
+	primary bool
+	imports []string
+}
+
+type fileStruct struct {
+	spec    *ast.StructType
+	pos     token.Pos
+	imports map[string]string // name => path
+}
+
+// modelsPackage declarations of the models package
+type modelsPackage struct {
+	dir        string
+	name       string
+	structs    map[string]*fileStruct
+	tableNames map[string]bool
+	types      map[string]bool // every declared type
+	qualify    bool            // generated package is another package, local types need the package name
+}
+
+// relationSettings gorm tag settings only valid on relation fields
+var relationSettings = []string{"FOREIGNKEY", "REFERENCES", "MANY2MANY", "POLYMORPHIC", "JOINFOREIGNKEY", "JOINREFERENCES"}
+
+// parseModels parse go files of dir, every struct with TableName method is a model.
+// local types are qualified by the models package when qualify is true
+func parseModels(dir string, qualify bool) ([]*model, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		fset = token.NewFileSet()
+		pkg  = &modelsPackage{
+			dir:        dir,
+			structs:    map[string]*fileStruct{},
+			tableNames: map[string]bool{},
+			types:      map[string]bool{},
+			qualify:    qualify,
+		}
+		structs    = pkg.structs
+		tableNames = pkg.tableNames
+	)
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg.name = file.Name.Name
+		imports := fileImports(file)
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					typeSpec, ok := spec.(*ast.TypeSpec)
+					if !ok || typeSpec.TypeParams != nil {
+						continue
+					}
+					pkg.types[typeSpec.Name.Name] = true
+					if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+						structs[typeSpec.Name.Name] = &fileStruct{spec: structType, pos: typeSpec.Pos(), imports: imports}
+					}
+				}
+
+			case *ast.FuncDecl:
+				if name, ok := tableNameReceiver(decl); ok {
+					tableNames[name] = true
+				}
+			}
+		}
+	}
+
+	var models []*model
+	for name, st := range structs {
+		if !tableNames[name] || !ast.IsExported(name) {
+			continue
+		}
+
+		m := &model{Name: name}
+		if err := m.walk(pkg, st, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		m.resolvePrimaryKey()
+		models = append(models, m)
+	}
+
+	// stable output regardless of map order
+	sortModels(models, structs, fset)
+	return models, nil
+}
+
+// sortModels in declared order of files
+func sortModels(models []*model, structs map[string]*fileStruct, fset *token.FileSet) {
+	slices.SortFunc(models, func(a, b *model) int {
+		posA, posB := fset.Position(structs[a.Name].pos), fset.Position(structs[b.Name].pos)
+		if posA.Filename != posB.Filename {
+			return strings.Compare(posA.Filename, posB.Filename)
+		}
+		return posA.Offset - posB.Offset
+	})
+}
+
+// tableNameReceiver return receiver type name of `func (T) TableName() string`
+func tableNameReceiver(decl *ast.FuncDecl) (string, bool) {
+	if decl.Name.Name != "TableName" || decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return "", false
+	}
+	if decl.Type.Params.NumFields() != 0 || decl.Type.Results.NumFields() != 1 {
+		return "", false
+	}
+
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+func fileImports(file *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+func (m *model) walk(pkg *modelsPackage, st *fileStruct, prefix string) (err error) {
+	for _, field := range st.spec.Fields.List {
+		settings := gormSettings(field)
+		if value, ok := settings["-"]; ok && (value == "-" || value == "all") {
+			continue
+		}
+
+		// embedded struct
+		if len(field.Names) == 0 {
+			if err := m.walkEmbedded(pkg, st, field.Type, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			if _, ok := settings["EMBEDDED"]; ok {
+				if err := m.walkEmbedded(pkg, st, field.Type, prefix+settings["EMBEDDEDPREFIX"]); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if pkg.isRelation(field.Type, settings) {
+				m.Relations = append(m.Relations, name.Name)
+				continue
+			}
+
+			col := column{
+				Field: name.Name,
+				Name:  settings["COLUMN"],
+			}
+			if col.Name == "" {
+				col.Name = prefix + naming.ColumnName("", name.Name)
+			}
+			_, col.primary = settings["PRIMARYKEY"]
+			if _, ok := settings["PRIMARY_KEY"]; ok {
+				col.primary = true
+			}
+			if col.Type, err = pkg.qualifiedType(field.Type); err != nil {
+				return err
+			}
+			col.imports = typeImports(field.Type, st.imports)
+			if pkg.qualify && pkg.hasLocalType(field.Type) {
+				spec, err := pkg.importSpec()
+				if err != nil {
+					return err
+				}
+				col.imports = append(col.imports, spec)
+			}
+			m.Columns = append(m.Columns, col)
+		}
+	}
+	return nil
+}
+
+func (m *model) walkEmbedded(pkg *modelsPackage, st *fileStruct, expr ast.Expr, prefix string) error {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		embedded, ok := pkg.structs[typ.Name]
+		if !ok {
+			return fmt.Errorf("embedded type %s is not a struct of the package", typ.Name)
+		}
+		return m.walk(pkg, embedded, prefix)
+
+	case *ast.SelectorExpr:
+		if pkg, ok := typ.X.(*ast.Ident); ok && st.imports[pkg.Name] == "gorm.io/gorm" && typ.Sel.Name == "Model" {
+			m.Columns = append(m.Columns,
+				column{Field: "ID", Name: prefix + "id", Type: "uint", primary: true},
+				column{Field: "CreatedAt", Name: prefix + "created_at", Type: "time.Time"},
+				column{Field: "UpdatedAt", Name: prefix + "updated_at", Type: "time.Time"},
+				column{Field: "DeletedAt", Name: prefix + "deleted_at", Type: "gorm.DeletedAt"},
+			)
+			return nil
+		}
+		return fmt.Errorf("embedded type %s of other package is not supported", types.ExprString(typ))
+	}
+
+	return fmt.Errorf("embedded type %s is not supported", types.ExprString(expr))
+}
+
+// resolvePrimaryKey follow gorm, field ID is the primary key when no field is tagged
+func (m *model) resolvePrimaryKey() {
+	var primaries []int
+	for i, col := range m.Columns {
+		if col.primary {
+			primaries = append(primaries, i)
+		}
+	}
+
+	if len(primaries) == 0 {
+		for i, col := range m.Columns {
+			if col.Field == "ID" {
+				m.Columns[i].primary = true
+				primaries = append(primaries, i)
+			}
+		}
+	}
+
+	if len(primaries) == 1 {
+		m.PrimaryKey = &m.Columns[primaries[0]]
+		m.Imports = m.PrimaryKey.imports
+	}
+}
+
+func gormSettings(field *ast.Field) map[string]string {
+	if field.Tag == nil {
+		return map[string]string{}
+	}
+
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return map[string]string{}
+	}
+	return schema.ParseTagSetting(reflect.StructTag(tag).Get("gorm"), ";")
+}
+
+// isRelation report whether field of expr type is a relation, its type is a model or it has relation settings
+func (pkg *modelsPackage) isRelation(expr ast.Expr, settings map[string]string) bool {
+	if _, ok := settings["SERIALIZER"]; ok {
+		return false
+	}
+
+	name, ok := baseIdent(expr)
+	if !ok || pkg.structs[name] == nil {
+		return false
+	}
+	if pkg.tableNames[name] {
+		return true
+	}
+
+	for _, key := range relationSettings {
+		if _, ok := settings[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLocalType report whether type expression reference a type declared in the models package
+func (pkg *modelsPackage) hasLocalType(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Ident:
+			found = found || pkg.types[node.Name]
+		}
+		return !found
+	})
+	return found
+}
+
+// qualifiedType type expression with local types qualified by the models package name
+func (pkg *modelsPackage) qualifiedType(expr ast.Expr) (string, error) {
+	if !pkg.qualify || !pkg.hasLocalType(expr) {
+		return types.ExprString(expr), nil
+	}
+
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		return pkg.name + "." + typ.Name, nil
+	case *ast.StarExpr:
+		elem, err := pkg.qualifiedType(typ.X)
+		return "*" + elem, err
+	case *ast.ArrayType:
+		elem, err := pkg.qualifiedType(typ.Elt)
+		if typ.Len == nil {
+			return "[]" + elem, err
+		}
+		return "[" + types.ExprString(typ.Len) + "]" + elem, err
+	case *ast.MapType:
+		key, err := pkg.qualifiedType(typ.Key)
+		if err != nil {
+			return "", err
+		}
+		value, err := pkg.qualifiedType(typ.Value)
+		return "map[" + key + "]" + value, err
+	}
+	return "", fmt.Errorf("type %s of the models package is not supported", types.ExprString(expr))
+}
+
+// importSpec import of the models package, named when its name differ from the base of its path
+func (pkg *modelsPackage) importSpec() (string, error) {
+	path, err := packagePath(pkg.dir)
+	if err != nil {
+		return "", err
+	}
+	return importSpec(pkg.name, path), nil
+}
+
+func importSpec(name, path string) string {
+	if filepath.Base(path) != name {
+		return name + " " + strconv.Quote(path)
+	}
+	return strconv.Quote(path)
+}
+
+// packagePath import path of dir from module path of the closest go.mod
+func packagePath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; {
+		content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			match := moduleDirective.FindSubmatch(content)
+			if match == nil {
+				return "", fmt.Errorf("no module directive in %s", filepath.Join(root, "go.mod"))
+			}
+
+			module := strings.Trim(string(match[1]), `"`)
+			rel, err := filepath.Rel(root, abs)
+			if err != nil || rel == "." {
+				return module, err
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", fmt.Errorf("no go.mod found for %s", dir)
+		}
+		root = parent
+	}
+}
+
+var moduleDirective = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// baseIdent strip pointer and slice of local type
+func baseIdent(expr ast.Expr) (string, bool) {
+	for {
+		switch typ := expr.(type) {
+		case *ast.StarExpr:
+			expr = typ.X
+		case *ast.ArrayType:
+			expr = typ.Elt
+		case *ast.Ident:
+			return typ.Name, true
+		default:
+			return "", false
+		}
+	}
+}
+
+// typeImports import specs referenced by type expression
+func typeImports(expr ast.Expr, imports map[string]string) []string {
+	var paths []string
+	ast.Inspect(expr, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := selector.X.(*ast.Ident); ok {
+			if path, ok := imports[pkg.Name]; ok {
+				paths = append(paths, importSpec(pkg.Name, path))
+			}
+		}
+		return false
+	})
+	return paths
+}
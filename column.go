@@ -0,0 +1,161 @@
+package gormqs
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+Column typed column of model M holding values of T, predicates only accept T
+
+	var UserBalance = gormqs.NewColumn[models.User, float64]("balance")
+
+	userQueries.GetMany(ctx, UserBalance.Gt(100))
+	// SQL: SELECT * FROM `users` WHERE `users`.`balance` > 100
+
+	UserBalance.Eq("abc") // compile error
+*/
+type Column[M Model, T any] struct {
+	name string
+}
+
+// NewColumn column name of model M, "table.column" keep its own table
+func NewColumn[M Model, T any](name string) Column[M, T] {
+	return Column[M, T]{name: name}
+}
+
+// Name of column without table
+func (c Column[M, T]) Name() string {
+	if i := strings.LastIndex(c.name, "."); i >= 0 {
+		return c.name[i+1:]
+	}
+	return c.name
+}
+
+func (c Column[M, T]) String() string {
+	return c.name
+}
+
+// column bind to table of the statement like WithTable, fallback to table of M
+func (c Column[M, T]) column(db *gorm.DB) clause.Column {
+	if i := strings.LastIndex(c.name, "."); i >= 0 {
+		return clause.Column{Table: c.name[:i], Name: c.name[i+1:]}
+	}
+
+	table := db.Statement.Table
+	if table == "" {
+		var model M
+		table = model.TableName()
+	}
+	return clause.Column{Table: table, Name: c.name}
+}
+
+// Eq column = value, nil pointer value is IS NULL
+func (c Column[M, T]) Eq(value T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Eq{Column: col, Value: value}
+	})
+}
+
+// Neq column <> value
+func (c Column[M, T]) Neq(value T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Neq{Column: col, Value: value}
+	})
+}
+
+// Gt column > value
+func (c Column[M, T]) Gt(value T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Gt{Column: col, Value: value}
+	})
+}
+
+// Gte column >= value
+func (c Column[M, T]) Gte(value T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Gte{Column: col, Value: value}
+	})
+}
+
+// Lt column < value
+func (c Column[M, T]) Lt(value T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Lt{Column: col, Value: value}
+	})
+}
+
+// Lte column <= value
+func (c Column[M, T]) Lte(value T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Lte{Column: col, Value: value}
+	})
+}
+
+// In column IN (values), no value match nothing
+func (c Column[M, T]) In(values ...T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.IN{Column: col, Values: toAnySlice(values)}
+	})
+}
+
+// NotIn column NOT IN (values), no value match non null rows
+func (c Column[M, T]) NotIn(values ...T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Not(clause.IN{Column: col, Values: toAnySlice(values)})
+	})
+}
+
+// Between column BETWEEN low AND high
+func (c Column[M, T]) Between(low, high T) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{col, low, high}}
+	})
+}
+
+// IsNull column IS NULL
+func (c Column[M, T]) IsNull() Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Expr{SQL: "? IS NULL", Vars: []any{col}}
+	})
+}
+
+// IsNotNull column IS NOT NULL
+func (c Column[M, T]) IsNotNull() Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []any{col}}
+	})
+}
+
+// Like column LIKE pattern, pattern is not escaped, see SafeTextForSql
+func (c Column[M, T]) Like(pattern string) Option {
+	return c.where(func(col clause.Column) clause.Expression {
+		return clause.Like{Column: col, Value: pattern}
+	})
+}
+
+// Asc sort by column ascending
+func (c Column[M, T]) Asc() Sort {
+	return Asc(c.name)
+}
+
+// Desc sort by column descending
+func (c Column[M, T]) Desc() Sort {
+	return Desc(c.name)
+}
+
+func (c Column[M, T]) where(build func(col clause.Column) clause.Expression) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(build(c.column(db)))
+	}
+}
+
+func toAnySlice[T any](values []T) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package gormqs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+)
+
+func TestColumn(t *testing.T) {
+	var (
+		id       = gormqs.NewColumn[testUser, uint]("id")
+		username = gormqs.NewColumn[testUser, *string]("username")
+		balance  = gormqs.NewColumn[testUser, float64]("balance")
+	)
+
+	tests := []struct {
+		name     string
+		opt      gormqs.Option
+		expected string
+	}{
+		{"eq", balance.Eq(10), "`users`.`balance` = ?"},
+		{"eq nil", username.Eq(nil), "`users`.`username` IS NULL"},
+		{"neq", balance.Neq(10), "`users`.`balance` <> ?"},
+		{"gt", balance.Gt(10), "`users`.`balance` > ?"},
+		{"lte", balance.Lte(10), "`users`.`balance` <= ?"},
+		{"in", id.In(1, 2, 3), "`users`.`id` IN (?,?,?)"},
+		{"not in", id.NotIn(1, 2), "`users`.`id` NOT IN (?,?)"},
+		{"between", balance.Between(1, 10), "`users`.`balance` BETWEEN ? AND ?"},
+		{"is null", username.IsNull(), "`users`.`username` IS NULL"},
+		{"like", username.Like("fox%"), "`users`.`username` LIKE ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qs, sqls := newTestQueries[testUser](t)
+			if _, err := qs.GetMany(context.Background(), tt.opt); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := "SELECT * FROM `users` WHERE " + tt.expected
+			if sql := lastSQL(t, sqls); sql != expected {
+				t.Errorf("got %s, want %s", sql, expected)
+			}
+		})
+	}
+}
@@ -6,18 +6,36 @@ import (
 	"gorm.io/gorm"
 )
 
-// ReplaceContext replace gorm.DB instance in context
+// ReplaceContext replace gorm.DB instance in context, OnCommit and OnRollback are bound to transaction of tx
 func ReplaceContext(tx *gorm.DB) context.Context {
-	tx.Statement.Context = ContextWithValue(tx.Statement.Context, tx)
+	return ReplaceNamedContext("", tx)
+}
+
+// ReplaceNamedContext same as ReplaceContext, tx is stored under name, see ContextWithDB
+func ReplaceNamedContext(name string, tx *gorm.DB) context.Context {
+	ctx := withCallbacks(tx.Statement.Context, tx)
+	tx.Statement.Context = ContextWithDB(ctx, name, tx)
 	return tx.Statement.Context
 }
 
 // WrapContext wrap gorm.DB instance in context
 func WrapContext(db *gorm.DB) context.Context {
-	exist := ContextValue[*gorm.DB](db.Statement.Context, nil)
+	return WrapNamedContext("", db)
+}
+
+/*
+WrapNamedContext wrap gorm.DB instance in context under name, queries with the same WithDBName use it
+
+	billingDB.Transaction(func(tx *gorm.DB) error {
+		ctx := gormqs.WrapNamedContext("billing", tx)
+		return invoiceQueries.CreateOne(ctx, invoice)
+	})
+*/
+func WrapNamedContext(name string, db *gorm.DB) context.Context {
+	exist := ContextDB(db.Statement.Context, name, nil)
 	if exist != nil {
 		return exist.Statement.Context
 	}
 
-	return ReplaceContext(db)
+	return ReplaceNamedContext(name, db)
 }
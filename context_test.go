@@ -0,0 +1,70 @@
+package gormqs_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+)
+
+func TestContextValue(t *testing.T) {
+	type requestID string
+
+	ctx := gormqs.ContextWithValue(context.Background(), requestID("abc"), 42)
+	if got := gormqs.ContextValue[requestID](ctx, ""); got != "abc" {
+		t.Errorf("expected abc, got %q", got)
+	}
+	if got := gormqs.ContextValue[int](ctx, 0); got != 42 {
+		t.Errorf("expected 42, got %d", got)
+	}
+	if got := gormqs.ContextValue[string](ctx, "fallback"); got != "fallback" {
+		t.Errorf("underlying type must not match, got %q", got)
+	}
+}
+
+func TestNamedContext(t *testing.T) {
+	var (
+		routes    = new([]string)
+		billing   = routedDB(t, "billing", routes)
+		billingTx = routedDB(t, "billing tx", routes)
+		catalog   = routedDB(t, "catalog", routes)
+		invoices  = gormqs.New[testUser](billing, gormqs.WithDBName("billing"))
+		products  = gormqs.New[testUser](catalog, gormqs.WithDBName("catalog"))
+	)
+
+	ctx := gormqs.ContextWithDB(context.Background(), "billing", billingTx)
+	_, _ = invoices.GetMany(ctx)
+	_, _ = products.GetMany(ctx)
+
+	// unnamed db of WrapContext does not shadow named queries
+	ctx = gormqs.ContextWithValue(context.Background(), billingTx)
+	_, _ = products.GetMany(ctx)
+
+	expected := []string{"billing tx", "catalog", "catalog"}
+	if !reflect.DeepEqual(*routes, expected) {
+		t.Errorf("expected %v, got %v", expected, *routes)
+	}
+
+	t.Run("transaction", func(t *testing.T) {
+		events := new([]string)
+		db, err := gorm.Open(savepointDialector{events: events}, &gorm.Config{ConnPool: txRecorder{events: events}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = gormqs.Transaction(context.Background(), db, func(ctx context.Context) error {
+			if gormqs.ContextDB(ctx, "billing", nil) == nil {
+				t.Error("tx is not bound to billing")
+			}
+			if gormqs.ContextDB(ctx, "", nil) != nil {
+				t.Error("tx is bound to default name")
+			}
+			return nil
+		}, gormqs.NamedTx("billing"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
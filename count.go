@@ -0,0 +1,166 @@
+package gormqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	countStrategyKey = "gormqs:count_strategy"
+	countCappedKey   = "gormqs:count_capped"
+)
+
+// CountStrategy count rows of query into dest, limit, offset and order are already removed by caller when needed
+type CountStrategy func(db *gorm.DB, dest *int64) error
+
+/*
+WithCountStrategy count with strategy instead of exact COUNT(*), used by Count, Queries.Count and GetListTo
+
+	total, err := qs.Count(ctx, WithCountStrategy(CappedCount(10000)))
+	qs.GetListTo(ctx, page, WithCountStrategy(EstimatedCount()))
+*/
+func WithCountStrategy(strategy CountStrategy) Option {
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Set(countStrategyKey, strategy)
+	}
+}
+
+// ExactCount SELECT count(*), the default strategy
+func ExactCount() CountStrategy {
+	return func(db *gorm.DB, dest *int64) error {
+		return db.Count(dest).Error
+	}
+}
+
+/*
+CappedCount count at most n rows, more rows are counted as n and reported as capped
+to resulter of GetListTo implementing ListCapper
+
+	// SQL: SELECT count(*) FROM (SELECT 1 FROM `users` WHERE balance > 0 LIMIT 10001) AS `capped`
+*/
+func CappedCount(n int) CountStrategy {
+	return func(db *gorm.DB, dest *int64) error {
+		// one more row tell whether the count is capped
+		inner := countOnly(db.Session(&gorm.Session{Initialized: true})).Select("1").Limit(n + 1)
+		if err := db.Session(&gorm.Session{NewDB: true}).Table("(?) AS capped", inner).Count(dest).Error; err != nil {
+			return err
+		}
+
+		capped := *dest > int64(n)
+		if capped {
+			*dest = int64(n)
+		}
+		if value, ok := db.Get(countCappedKey); ok {
+			*value.(*bool) = capped
+		}
+		return nil
+	}
+}
+
+// reportCapped record whether CappedCount reached its cap into capped
+func reportCapped(capped *bool) Option {
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Set(countCappedKey, capped)
+	}
+}
+
+/*
+EstimatedCount row estimation of the query planner on postgres and mysql, exact count on other dialects.
+estimation is fast but can be far from the real count, use it for display only
+*/
+func EstimatedCount() CountStrategy {
+	return func(db *gorm.DB, dest *int64) error {
+		switch db.Dialector.Name() {
+		case "postgres":
+			return explainCount(db, dest, "EXPLAIN (FORMAT JSON) ", postgresPlanRows)
+		case "mysql":
+			return explainCount(db, dest, "EXPLAIN FORMAT=JSON ", mysqlPlanRows)
+		}
+		return db.Count(dest).Error
+	}
+}
+
+// runCount count with the strategy of WithCountStrategy
+func runCount(db *gorm.DB, dest *int64) error {
+	value, _ := db.Get(countStrategyKey)
+	strategy, _ := value.(CountStrategy)
+	if strategy == nil {
+		strategy = ExactCount()
+	}
+	return strategy(db, dest)
+}
+
+// explainCount explain the query in JSON format and parse estimated rows with parse
+func explainCount(db *gorm.DB, dest *int64, explain string, parse func(plan []byte) (int64, error)) error {
+	// render sql only, the explain query below is logged and collected by DryRun
+	query := countOnly(db.Session(&gorm.Session{Initialized: true, DryRun: true, Logger: logger.Discard})).
+		Select("*").Find(&[]map[string]any{})
+	if query.Error != nil {
+		return query.Error
+	}
+
+	var plan string
+	if err := db.Session(&gorm.Session{NewDB: true}).Raw(explain+"?", query).Find(&plan).Error; err != nil {
+		return err
+	}
+	if db.DryRun {
+		*dest = 0
+		return nil
+	}
+
+	rows, err := parse([]byte(plan))
+	if err != nil {
+		return fmt.Errorf("gormqs: parse query plan: %w", err)
+	}
+	*dest = rows
+	return nil
+}
+
+func postgresPlanRows(plan []byte) (int64, error) {
+	var result []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &result); err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, fmt.Errorf("empty plan")
+	}
+	return int64(result[0].Plan.PlanRows), nil
+}
+
+// mysqlPlanRows rows produced by the first table of the plan
+func mysqlPlanRows(plan []byte) (int64, error) {
+	var result struct {
+		QueryBlock struct {
+			Table *struct {
+				RowsProducedPerJoin json.Number `json:"rows_produced_per_join"`
+			} `json:"table"`
+			NestedLoop []struct {
+				Table struct {
+					RowsProducedPerJoin json.Number `json:"rows_produced_per_join"`
+				} `json:"table"`
+			} `json:"nested_loop"`
+		} `json:"query_block"`
+	}
+	if err := json.Unmarshal(plan, &result); err != nil {
+		return 0, err
+	}
+
+	var rows json.Number
+	switch block := result.QueryBlock; {
+	case block.Table != nil:
+		rows = block.Table.RowsProducedPerJoin
+	case len(block.NestedLoop) > 0:
+		rows = block.NestedLoop[len(block.NestedLoop)-1].Table.RowsProducedPerJoin
+	default:
+		return 0, fmt.Errorf("no table in plan")
+	}
+	return strconv.ParseInt(rows.String(), 10, 64)
+}
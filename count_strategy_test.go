@@ -0,0 +1,115 @@
+package gormqs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"github.com/foxie-io/gormqs/pagination"
+	"gorm.io/gorm"
+)
+
+func TestCappedCount(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+
+	_, err := qs.Count(context.Background(),
+		gormqs.Where("balance > ?", 0),
+		gormqs.OrderBy(gormqs.Asc("id")),
+		gormqs.WithCountStrategy(gormqs.CappedCount(1000)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "SELECT count(*) FROM (SELECT 1 FROM `users` WHERE balance > ? LIMIT ?) AS capped"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
+
+func TestEstimatedCountFallback(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+
+	resulter := gormqs.NewListResulter[testUser](gormqs.SelectCount)
+	err := qs.GetListTo(context.Background(), resulter, gormqs.WithCountStrategy(gormqs.EstimatedCount()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dialect without planner estimation count exactly
+	expected := "SELECT count(*) FROM `users` "
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
+
+func TestEstimatedCountDryRun(t *testing.T) {
+	db, err := gorm.Open(postgresDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg := &testQueries[testUser]{db: db}
+	pg.Queries = gormqs.NewQueries[testUser](pg)
+
+	var collector gormqs.SQLCollector
+	ctx := gormqs.DryRun(context.Background(), &collector)
+	if _, err := pg.Count(ctx, gormqs.Where("balance > ?", 10), gormqs.WithCountStrategy(gormqs.EstimatedCount())); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := collector.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+
+	expected := "EXPLAIN (FORMAT JSON) SELECT * FROM `users` WHERE balance > ? "
+	if sql := statements[0].SQL; sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+	if vars := statements[0].Vars; len(vars) != 1 || vars[0] != 10 {
+		t.Errorf("got vars %v, want [10]", vars)
+	}
+}
+
+func TestCappedCountReportCapped(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+
+	// dry run count 5 rows
+	_ = qs.db.Callback().Query().After("gorm:query").Register("test:count", func(db *gorm.DB) {
+		if dest, ok := db.Statement.Dest.(*int64); ok {
+			*dest, db.RowsAffected = 5, 1
+		}
+	})
+
+	tests := []struct {
+		cap    int
+		count  int64
+		capped bool
+	}{
+		{cap: 3, count: 3, capped: true},
+		{cap: 5, count: 5, capped: false},
+	}
+
+	for _, test := range tests {
+		resulter := gormqs.NewListResulter[testUser](gormqs.SelectList | gormqs.SelectCount)
+		if err := qs.GetListTo(context.Background(), resulter, gormqs.WithCountStrategy(gormqs.CappedCount(test.cap))); err != nil {
+			t.Fatal(err)
+		}
+		if *resulter.Count != test.count || resulter.Capped != test.capped {
+			t.Errorf("cap %d: got count %d capped %v, want %d %v", test.cap, *resulter.Count, resulter.Capped, test.count, test.capped)
+		}
+
+		page, _ := pagination.New[*testUser](1, 10, gormqs.SelectCount)
+		if err := qs.GetListTo(context.Background(), page, gormqs.WithCountStrategy(gormqs.CappedCount(test.cap))); err != nil {
+			t.Fatal(err)
+		}
+		if *page.Total != test.count || page.Capped != test.capped {
+			t.Errorf("cap %d: got page total %d capped %v, want %d %v", test.cap, *page.Total, page.Capped, test.count, test.capped)
+		}
+	}
+
+	expected := "SELECT count(*) FROM (SELECT 1 FROM `users` LIMIT ?) AS capped"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
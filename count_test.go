@@ -0,0 +1,91 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestCount(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+
+	var total int64
+	_, err := qs.GetMany(context.Background(),
+		gormqs.Count(&total),
+		gormqs.Where("balance > ?", 10),
+		gormqs.OrderBy(gormqs.Desc("id")),
+		gormqs.LimitAndOffset(10, 20),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"SELECT * FROM `users` WHERE balance > ? ORDER BY `users`.`id` DESC LIMIT ? OFFSET ?",
+		"SELECT count(*) FROM `users` WHERE balance > ? ",
+	}
+	if len(*sqls) != len(expected) {
+		t.Fatalf("got %v, want %v", *sqls, expected)
+	}
+	for i, sql := range *sqls {
+		if sql != expected[i] {
+			t.Errorf("got %s, want %s", sql, expected[i])
+		}
+	}
+}
+
+func TestCountError(t *testing.T) {
+	qs, _ := newTestQueries[testUser](t)
+
+	errCount := errors.New("count failed")
+	failed := func(db *gorm.DB) *gorm.DB {
+		_ = db.AddError(errCount)
+		return db
+	}
+
+	var total int64
+	if _, err := qs.GetMany(context.Background(), gormqs.Count(&total, failed)); !errors.Is(err, errCount) {
+		t.Errorf("got %v, want count error", err)
+	}
+}
+
+func TestConcurrentCount(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qs := &testQueries[testUser]{db: db}
+	qs.Queries = gormqs.NewQueries[testUser](qs)
+
+	var collector gormqs.SQLCollector
+	ctx := gormqs.DryRun(context.Background(), &collector)
+
+	resulter := gormqs.NewListResulter[testUser](gormqs.SelectList | gormqs.SelectCount)
+	if err := qs.GetListTo(ctx, resulter, gormqs.ConcurrentCount(), gormqs.LimitAndOffset(10, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if statements := collector.Statements(); len(statements) != 2 {
+		t.Errorf("got %v, want list and count", statements)
+	}
+
+	errCount := errors.New("count failed")
+	failed := func(db *gorm.DB) *gorm.DB {
+		if _, isCount := db.Statement.Dest.(*int64); isCount {
+			_ = db.AddError(errCount)
+		}
+		return db
+	}
+
+	resulter = gormqs.NewListResulter[testUser](gormqs.SelectList | gormqs.SelectCount)
+	err = qs.GetListTo(ctx, resulter, gormqs.ConcurrentCount(), func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(failed)
+	}, gormqs.LimitAndOffset(10, 0))
+	if !errors.Is(err, errCount) {
+		t.Errorf("got %v, want count error", err)
+	}
+}
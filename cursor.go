@@ -0,0 +1,286 @@
+package gormqs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidCursor = errors.New("gormqs: invalid cursor")
+
+var (
+	// CursorResulter implement interface ListOrCountResulter
+	_ ListOrCountResulter = (*CursorResulter[Model])(nil)
+	_ ListOptioner        = (*CursorResulter[Model])(nil)
+	_ ListFinalizer       = (*CursorResulter[Model])(nil)
+)
+
+// cursor direction
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+// cursorToken is the decoded form of an opaque cursor
+type cursorToken struct {
+	Direction string            `json:"d"`
+	Values    []json.RawMessage `json:"v"`
+}
+
+type cursorKey struct {
+	sort  Sort
+	field *schema.Field
+}
+
+/*
+CursorResulter keyset pagination, primary key is added as tie-breaker when missing
+
+	resulter := NewCursorResulter[User](r.URL.Query().Get("cursor"), 20, Desc("created_at"))
+	err := qs.GetListTo(ctx, resulter, Where("balance > ?", 0))
+
+	// resulter.NextCursor, resulter.PrevCursor are empty when there is no more page
+
+key columns must not be null
+*/
+type CursorResulter[T Model] struct {
+	cursor   string
+	limit    int
+	sorts    []Sort
+	keys     []cursorKey
+	backward bool
+
+	List       *[]*T  `json:"list,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+func NewCursorResulter[T Model](cursor string, limit int, sorts ...Sort) *CursorResulter[T] {
+	if limit < 1 {
+		limit = 1
+	}
+
+	return &CursorResulter[T]{
+		cursor: cursor,
+		limit:  limit,
+		sorts:  sorts,
+	}
+}
+
+func (r *CursorResulter[T]) QsList() any {
+	if r.List == nil {
+		r.List = new([]*T)
+	}
+	return r.List
+}
+
+func (r *CursorResulter[T]) QsCount() *int64 {
+	return nil
+}
+
+func (r *CursorResulter[T]) QsListOption() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		tx := db.Limit(r.limit + 1).Offset(-1)
+
+		sch, err := parseSchema(tx, new(T))
+		if err != nil {
+			tx.AddError(err)
+			return tx
+		}
+
+		if r.keys, err = cursorKeys(sch, r.sorts); err != nil {
+			tx.AddError(err)
+			return tx
+		}
+
+		var values []any
+		if r.cursor != "" {
+			var direction string
+			if direction, values, err = decodeCursor(r.cursor, r.keys); err != nil {
+				tx.AddError(err)
+				return tx
+			}
+			r.backward = direction == cursorPrev
+		}
+
+		sorts := make([]Sort, len(r.keys))
+		for i, key := range r.keys {
+			sorts[i] = key.sort
+			if r.backward {
+				sorts[i] = key.sort.reverse()
+			}
+		}
+
+		if len(values) > 0 {
+			tx = tx.Where(keysetCondition(sorts, values))
+		}
+
+		// keyset order must be the only order
+		delete(tx.Statement.Clauses, "ORDER BY")
+		for _, sort := range sorts {
+			tx = tx.Order(sort.orderByColumn())
+		}
+
+		return tx
+	}
+}
+
+func (r *CursorResulter[T]) QsAfterList() error {
+	list := *r.List
+	hasMore := len(list) > r.limit
+	if hasMore {
+		list = list[:r.limit]
+	}
+
+	if r.backward {
+		slices.Reverse(list)
+	}
+	*r.List = list
+
+	r.NextCursor, r.PrevCursor = "", ""
+	if len(list) == 0 {
+		return nil
+	}
+
+	var err error
+	if hasMore || r.backward {
+		if r.NextCursor, err = encodeCursor(cursorNext, r.keys, list[len(list)-1]); err != nil {
+			return err
+		}
+	}
+
+	if (hasMore && r.backward) || (!r.backward && r.cursor != "") {
+		if r.PrevCursor, err = encodeCursor(cursorPrev, r.keys, list[0]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cursorKeys resolve sort columns to fields and add primary keys as tie-breaker
+func cursorKeys(sch *schema.Schema, sorts []Sort) ([]cursorKey, error) {
+	keys := make([]cursorKey, 0, len(sorts)+len(sch.PrimaryFields))
+	seen := map[string]bool{}
+
+	for _, sort := range sorts {
+		field := sch.LookUpField(sort.Column)
+		if field == nil {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidCursor, sort.Column)
+		}
+
+		if sort.Nulls != NullsDefault {
+			return nil, fmt.Errorf("%w: nulls placement of %q is not supported", ErrInvalidCursor, sort.Column)
+		}
+
+		sort.Column = field.DBName
+		keys = append(keys, cursorKey{sort: sort, field: field})
+		seen[field.DBName] = true
+	}
+
+	tieBreaker := Asc("")
+	if len(keys) > 0 {
+		tieBreaker = keys[len(keys)-1].sort
+	}
+
+	for _, field := range sch.PrimaryFields {
+		if seen[field.DBName] {
+			continue
+		}
+
+		tieBreaker.Column = field.DBName
+		keys = append(keys, cursorKey{sort: tieBreaker, field: field})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no key column", ErrInvalidCursor)
+	}
+
+	return keys, nil
+}
+
+/*
+keysetCondition seek after values
+
+	(a > 1) OR (a = 1 AND b < 2) OR (a = 1 AND b = 2 AND id > 3)
+*/
+func keysetCondition(sorts []Sort, values []any) clause.Expression {
+	ors := make([]clause.Expression, len(sorts))
+	for i, sort := range sorts {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := range i {
+			ands = append(ands, clause.Eq{Column: sorts[j].orderByColumn().Column, Value: values[j]})
+		}
+
+		column := sort.orderByColumn().Column
+		if sort.Desc {
+			ands = append(ands, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			ands = append(ands, clause.Gt{Column: column, Value: values[i]})
+		}
+
+		ors[i] = clause.And(ands...)
+	}
+
+	return clause.Or(ors...)
+}
+
+func encodeCursor(direction string, keys []cursorKey, row any) (string, error) {
+	token := cursorToken{Direction: direction, Values: make([]json.RawMessage, len(keys))}
+
+	rv := reflect.Indirect(reflect.ValueOf(row))
+	for i, key := range keys {
+		value, _ := key.field.ValueOf(context.Background(), rv)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		token.Values[i] = raw
+	}
+
+	b, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor decode values back to the go type of each key field
+func decodeCursor(cursor string, keys []cursorKey) (direction string, values []any, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(b, &token); err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if token.Direction != cursorNext && token.Direction != cursorPrev {
+		return "", nil, fmt.Errorf("%w: unknown direction %q", ErrInvalidCursor, token.Direction)
+	}
+
+	if len(token.Values) != len(keys) {
+		return "", nil, fmt.Errorf("%w: expect %d values, got %d", ErrInvalidCursor, len(keys), len(token.Values))
+	}
+
+	values = make([]any, len(keys))
+	for i, key := range keys {
+		value := reflect.New(key.field.FieldType)
+		if err := json.Unmarshal(token.Values[i], value.Interface()); err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+		}
+		values[i] = value.Elem().Interface()
+	}
+
+	return token.Direction, values, nil
+}
@@ -0,0 +1,139 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestCursorResulterFirstPage(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+
+	resulter := gormqs.NewCursorResulter[testUser]("", 10, gormqs.Desc("Balance"))
+	if err := qs.GetListTo(context.Background(), resulter, gormqs.Where("balance > ?", 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "SELECT * FROM `users` WHERE balance > ? ORDER BY `users`.`balance` DESC,`users`.`id` DESC LIMIT ?"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+
+	if resulter.NextCursor != "" || resulter.PrevCursor != "" {
+		t.Errorf("expect no cursor on empty page, got next=%q prev=%q", resulter.NextCursor, resulter.PrevCursor)
+	}
+}
+
+func TestCursorResulterPages(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+	ctx := context.Background()
+
+	// dry run return rows of the current page and record vars
+	var (
+		rows []*testUser
+		vars []any
+	)
+	_ = qs.db.Callback().Query().After("gorm:query").Register("test:rows", func(db *gorm.DB) {
+		vars = db.Statement.Vars
+		if dest, ok := db.Statement.Dest.(*[]*testUser); ok {
+			*dest = rows
+		}
+	})
+
+	page := func(cursor string, result ...*testUser) *gormqs.CursorResulter[testUser] {
+		t.Helper()
+		rows = result
+		resulter := gormqs.NewCursorResulter[testUser](cursor, 2, gormqs.Desc("Balance"), gormqs.Asc("Username"))
+		if err := qs.GetListTo(ctx, resulter); err != nil {
+			t.Fatal(err)
+		}
+		return resulter
+	}
+
+	ids := func(list []*testUser) []uint {
+		result := make([]uint, len(list))
+		for i, user := range list {
+			result[i] = user.ID
+		}
+		return result
+	}
+
+	// first page fetch one more row to detect next page
+	first := page("", &testUser{ID: 1, Username: "a", Balance: 30}, &testUser{ID: 2, Username: "b", Balance: 20}, &testUser{ID: 3, Username: "c", Balance: 20})
+	if got := ids(*first.List); !slices.Equal(got, []uint{1, 2}) {
+		t.Fatalf("got ids %v, want [1 2]", got)
+	}
+	if first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("got next=%q prev=%q, want next only", first.NextCursor, first.PrevCursor)
+	}
+
+	// next page seek after the last row, primary key break the tie
+	second := page(first.NextCursor, &testUser{ID: 3, Username: "c", Balance: 20}, &testUser{ID: 4, Username: "d", Balance: 10})
+	expected := "SELECT * FROM `users` WHERE (`users`.`balance` < ? OR (`users`.`balance` = ? AND `users`.`username` > ?) OR (`users`.`balance` = ? AND `users`.`username` = ? AND `users`.`id` > ?)) ORDER BY `users`.`balance` DESC,`users`.`username`,`users`.`id` LIMIT ?"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+	if !slices.Equal(vars, []any{20.0, 20.0, "b", 20.0, "b", uint(2), 3}) {
+		t.Errorf("got vars %v, want [20 20 b 20 b 2 3]", vars)
+	}
+	if second.NextCursor != "" || second.PrevCursor == "" {
+		t.Fatalf("got next=%q prev=%q, want prev only", second.NextCursor, second.PrevCursor)
+	}
+
+	// prev page seek before the first row in reversed order, rows are returned in reversed order
+	prev := page(second.PrevCursor, &testUser{ID: 2, Username: "b", Balance: 20}, &testUser{ID: 1, Username: "a", Balance: 30})
+	expected = "SELECT * FROM `users` WHERE (`users`.`balance` > ? OR (`users`.`balance` = ? AND `users`.`username` < ?) OR (`users`.`balance` = ? AND `users`.`username` = ? AND `users`.`id` < ?)) ORDER BY `users`.`balance`,`users`.`username` DESC,`users`.`id` DESC LIMIT ?"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+	if !slices.Equal(vars, []any{20.0, 20.0, "c", 20.0, "c", uint(3), 3}) {
+		t.Errorf("got vars %v, want [20 20 c 20 c 3 3]", vars)
+	}
+
+	// round trip back to the first page
+	if got := ids(*prev.List); !slices.Equal(got, []uint{1, 2}) {
+		t.Errorf("got ids %v, want [1 2]", got)
+	}
+	if prev.NextCursor != first.NextCursor || prev.PrevCursor != "" {
+		t.Errorf("got next=%q prev=%q, want next=%q only", prev.NextCursor, prev.PrevCursor, first.NextCursor)
+	}
+}
+
+func TestCursorResulterNamingStrategy(t *testing.T) {
+	lower, _ := newTestQueries[testUser](t)
+	resulter := gormqs.NewCursorResulter[testUser]("", 10, gormqs.Desc("Balance"))
+	if err := lower.GetListTo(context.Background(), resulter); err != nil {
+		t.Fatal(err)
+	}
+
+	// schema parsed for another db must not leak its column names
+	qs, sqls := newTestQueries[testUser](t)
+	qs.db.NamingStrategy = schema.NamingStrategy{NoLowerCase: true}
+
+	resulter = gormqs.NewCursorResulter[testUser]("", 10, gormqs.Desc("Balance"))
+	if err := qs.GetListTo(context.Background(), resulter); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "SELECT * FROM `users` ORDER BY `users`.`Balance` DESC,`users`.`ID` DESC LIMIT ?"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
+
+func TestCursorResulterInvalidCursor(t *testing.T) {
+	qs, _ := newTestQueries[testUser](t)
+
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "eyJkIjoieCIsInYiOltdfQ"} {
+		resulter := gormqs.NewCursorResulter[testUser](cursor, 10)
+		err := qs.GetListTo(context.Background(), resulter)
+		if !errors.Is(err, gormqs.ErrInvalidCursor) {
+			t.Errorf("cursor %q: got %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
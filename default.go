@@ -0,0 +1,141 @@
+package gormqs
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+var (
+	_ Querier = (*DefaultQuerier[Model])(nil)
+)
+
+// Config of DefaultQuerier
+type Config struct {
+	options   []Option
+	tableName string
+	scopes    []func(*gorm.DB) *gorm.DB
+	resolver  *Resolver
+	dbName    string
+}
+
+type ConfigOption func(*Config)
+
+// WithDefaultOptions apply options to every query before the query options
+func WithDefaultOptions(opts ...Option) ConfigOption {
+	return func(c *Config) {
+		c.options = append(c.options, opts...)
+	}
+}
+
+// WithTableName override table name of model, e.g. partitioned or archived table
+func WithTableName(tableName string) ConfigOption {
+	return func(c *Config) {
+		c.tableName = tableName
+	}
+}
+
+/*
+WithScopes add gorm scopes to every query, scopes are applied when the query is executed
+
+	gormqs.New[models.User](db, gormqs.WithScopes(func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at IS NULL")
+	}))
+*/
+func WithScopes(scopes ...func(*gorm.DB) *gorm.DB) ConfigOption {
+	return func(c *Config) {
+		c.scopes = append(c.scopes, scopes...)
+	}
+}
+
+// WithResolver route reads to replicas of resolver and writes to its primary, see Resolver
+func WithResolver(resolver *Resolver) ConfigOption {
+	return func(c *Config) {
+		c.resolver = resolver
+	}
+}
+
+/*
+WithDBName bind queries to the connection name of ctx, tx of other databases in ctx is never used
+
+	billingCtx := gormqs.WrapNamedContext("billing", billingTx)
+	invoiceQueries := gormqs.New[models.Invoice](billingDB, gormqs.WithDBName("billing"))
+	productQueries := gormqs.New[models.Product](catalogDB, gormqs.WithDBName("catalog"))
+
+	invoiceQueries.CreateOne(billingCtx, invoice) // billingTx
+	productQueries.GetMany(billingCtx)            // catalogDB
+*/
+func WithDBName(name string) ConfigOption {
+	return func(c *Config) {
+		c.dbName = name
+	}
+}
+
+// DefaultQuerier standard context aware DBInstance used by New
+type DefaultQuerier[M Model] struct {
+	db     *gorm.DB
+	model  M
+	config Config
+}
+
+// DefaultQueries Queries of DefaultQuerier, embed it to add domain methods
+type DefaultQueries[M Model] = Queries[M, *DefaultQuerier[M]]
+
+/*
+New Queries without writing DBInstance
+
+	userQueries := gormqs.New[models.User](db)
+
+embed to add domain methods
+
+	type UserQueries struct {
+		gormqs.DefaultQueries[models.User]
+	}
+
+	func NewUserQueries(db *gorm.DB) *UserQueries {
+		return &UserQueries{gormqs.New[models.User](db)}
+	}
+
+	func (qs *UserQueries) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+		return qs.GetOne(ctx, gormqs.Where("username = ?", username))
+	}
+*/
+func New[M Model](db *gorm.DB, cfgOpts ...ConfigOption) DefaultQueries[M] {
+	querier := &DefaultQuerier[M]{db: db}
+	for _, opt := range cfgOpts {
+		opt(&querier.config)
+	}
+	return NewQueries[M](querier)
+}
+
+// DBInstance use transaction of context when exists, resolver of WithResolver otherwise
+func (q *DefaultQuerier[M]) DBInstance(ctx context.Context) *gorm.DB {
+	tableName := q.config.tableName
+	if tableName == "" {
+		tableName = q.model.TableName()
+	}
+
+	db := ContextDB(ctx, q.config.dbName, nil)
+	if db == nil {
+		db = q.db
+		if q.config.resolver != nil {
+			db = q.config.resolver.pick(ctx)
+		}
+	}
+
+	db = db.WithContext(ctx).Table(tableName).Model(q.model)
+	if len(q.config.scopes) > 0 {
+		db = db.Scopes(q.config.scopes...)
+	}
+	return Apply(db, q.config.options)
+}
+
+// DB without context
+func (q *DefaultQuerier[M]) DB() *gorm.DB {
+	return q.db
+}
+
+// DBName connection name of WithDBName
+func (q *DefaultQuerier[M]) DBName() string {
+	return q.config.dbName
+}
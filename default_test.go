@@ -0,0 +1,54 @@
+package gormqs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type testUserQueries struct {
+	gormqs.DefaultQueries[testUser]
+}
+
+func (qs *testUserQueries) GetByUsername(ctx context.Context, username string) (*testUser, error) {
+	return qs.GetOne(ctx, gormqs.Where("username = ?", username))
+}
+
+func TestNew(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qs := &testUserQueries{gormqs.New[testUser](db,
+		gormqs.WithTableName("users_archive"),
+		gormqs.WithDefaultOptions(gormqs.Where("balance > ?", 0)),
+		gormqs.WithScopes(func(db *gorm.DB) *gorm.DB {
+			return db.Where("deleted_at IS NULL")
+		}),
+	)}
+
+	var collector gormqs.SQLCollector
+	ctx := gormqs.DryRun(context.Background(), &collector)
+
+	if _, err := qs.GetByUsername(ctx, "foxie"); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := collector.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+
+	expected := "SELECT * FROM `users_archive` WHERE balance > ? AND username = ? AND deleted_at IS NULL ORDER BY `users_archive`.`id` LIMIT ?"
+	if sql := statements[0].SQL; sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+
+	if qs.Querier().DB() != db {
+		t.Error("querier must keep db")
+	}
+}
@@ -0,0 +1,135 @@
+package gormqs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// CollectedSQL statement rendered in dry run
+type CollectedSQL struct {
+	SQL  string
+	Vars []any
+}
+
+// SQLCollector collect statements rendered in dry run, safe for concurrent use
+type SQLCollector struct {
+	mu         sync.Mutex
+	statements []CollectedSQL
+}
+
+func (c *SQLCollector) add(sql string, vars []any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, CollectedSQL{SQL: sql, Vars: slices.Clone(vars)})
+}
+
+// Statements in executed order
+func (c *SQLCollector) Statements() []CollectedSQL {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.statements)
+}
+
+/*
+DryRun make every Queries method render sql into collector instead of hitting the database
+
+	var collector gormqs.SQLCollector
+	ctx := gormqs.DryRun(ctx, &collector)
+
+	err := qs.GetListTo(ctx, resulter, qopt.USER.Where(qopt.USER.Balance, ">", 0))
+
+	for _, stmt := range collector.Statements() {
+		log.Println(stmt.SQL, stmt.Vars) // list and count query
+	}
+
+results are empty and affected rows are zero in dry run
+*/
+func DryRun(ctx context.Context, collector *SQLCollector) context.Context {
+	return ContextWithValue(ctx, collector)
+}
+
+// instance of querier with dry run and options applied
+func instance(ctx context.Context, q Querier, opts []Option) *gorm.DB {
+	db := withDryRun(q.DBInstance(ctx))
+	db = Apply(db, opts)
+
+	// option may replace logger, e.g. WithDebug
+	return withDryRun(db)
+}
+
+// withDryRun switch db to dry run when context has SQLCollector
+func withDryRun(db *gorm.DB) *gorm.DB {
+	if !isDryRun(db) {
+		return db
+	}
+
+	if _, ok := db.Logger.(dryRunLogger); ok && db.DryRun {
+		return db
+	}
+
+	return db.Session(&gorm.Session{
+		DryRun: true,
+		Logger: dryRunLogger{Interface: db.Logger},
+	})
+}
+
+// isDryRun report whether db is switched to dry run by DryRun context
+func isDryRun(db *gorm.DB) bool {
+	return ContextValue[*SQLCollector](db.Statement.Context, nil) != nil
+}
+
+// isDryRunErr rows can not be returned in dry run, sql is already collected
+func isDryRunErr(db *gorm.DB, err error) bool {
+	return isDryRun(db) && errors.Is(err, gorm.ErrDryRunModeUnsupported)
+}
+
+var (
+	_ logger.Interface  = dryRunLogger{}
+	_ gorm.ParamsFilter = dryRunLogger{}
+)
+
+// dryRunLogger collect sql and vars of every statement into SQLCollector of context
+type dryRunLogger struct {
+	logger.Interface
+}
+
+func (l dryRunLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return dryRunLogger{Interface: l.Interface.LogMode(level)}
+}
+
+func (l dryRunLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	var (
+		sql    string
+		rows   int64
+		traced bool
+	)
+
+	// fc collect statement through ParamsFilter, make sure it is called exactly once
+	once := func() (string, int64) {
+		if !traced {
+			traced = true
+			sql, rows = fc()
+		}
+		return sql, rows
+	}
+
+	once()
+	l.Interface.Trace(ctx, begin, once, err)
+}
+
+func (l dryRunLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	if collector := ContextValue[*SQLCollector](ctx, nil); collector != nil {
+		collector.add(sql, params)
+	}
+
+	if filter, ok := l.Interface.(gorm.ParamsFilter); ok {
+		return filter.ParamsFilter(ctx, sql, params...)
+	}
+	return sql, params
+}
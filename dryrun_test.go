@@ -0,0 +1,55 @@
+package gormqs_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestDryRun(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qs := &testQueries[testVersionedUser]{db: db}
+	qs.Queries = gormqs.NewQueries[testVersionedUser](qs)
+
+	var collector gormqs.SQLCollector
+	ctx := gormqs.DryRun(context.Background(), &collector)
+
+	var (
+		list  []*testVersionedUser
+		count int64
+	)
+	err = qs.GetManyTo(ctx, &list, gormqs.Where("balance > ?", 10), gormqs.Count(&count))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := testVersionedUser{ID: 1, Balance: 100, Version: 3}
+	if _, err := qs.Update(ctx, &user, gormqs.Select("balance")); err != nil {
+		t.Fatalf("dry run update must not be stale, got %v", err)
+	}
+	if user.Version != 3 {
+		t.Errorf("version must be restored in dry run, got %d", user.Version)
+	}
+
+	if _, err := gormqs.Exists(ctx, qs, gormqs.Where("id = ?", 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []gormqs.CollectedSQL{
+		{SQL: "SELECT * FROM `users` WHERE balance > ?", Vars: []any{10}},
+		{SQL: "SELECT count(*) FROM `users` WHERE balance > ? ", Vars: []any{10}},
+		{SQL: "UPDATE `users` SET `balance`=?,`version`=? WHERE `users`.`version` = ? AND `id` = ?", Vars: []any{100.0, int64(4), int64(3), uint(1)}},
+		{SQL: "SELECT 1 FROM `users` WHERE id = ? LIMIT ?", Vars: []any{2, 1}},
+	}
+	if statements := collector.Statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("got %v, want %v", statements, expected)
+	}
+}
@@ -0,0 +1,461 @@
+package gormqs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound     = errors.New("gormqs: record not found")
+	ErrMissingWhere = errors.New("gormqs: refuse to update or delete without WHERE clause, use AllowFullTable() to run on full table")
+)
+
+var (
+	_ error = (*ErrDuplicate)(nil)
+	_ error = (*ErrForeignKey)(nil)
+	_ error = (*ErrCheckViolation)(nil)
+)
+
+/*
+ErrDuplicate unique or primary key violation
+
+	var dup *gormqs.ErrDuplicate
+	if errors.As(err, &dup) {
+		log.Println(dup.Constraint, dup.Columns)
+	}
+
+	errors.Is(err, &gormqs.ErrDuplicate{}) // true for any duplicate
+*/
+type ErrDuplicate struct {
+	Constraint string
+	Columns    []string
+	Err        error
+}
+
+func (e *ErrDuplicate) Error() string {
+	return constraintMessage("duplicate key", e.Constraint, e.Columns, e.Err)
+}
+
+func (e *ErrDuplicate) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrDuplicate) Is(target error) bool {
+	_, ok := target.(*ErrDuplicate)
+	return ok
+}
+
+// ErrForeignKey foreign key violation
+type ErrForeignKey struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ErrForeignKey) Error() string {
+	return constraintMessage("foreign key violation", e.Constraint, nil, e.Err)
+}
+
+func (e *ErrForeignKey) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrForeignKey) Is(target error) bool {
+	_, ok := target.(*ErrForeignKey)
+	return ok
+}
+
+// ErrCheckViolation check constraint violation
+type ErrCheckViolation struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ErrCheckViolation) Error() string {
+	return constraintMessage("check violation", e.Constraint, nil, e.Err)
+}
+
+func (e *ErrCheckViolation) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrCheckViolation) Is(target error) bool {
+	_, ok := target.(*ErrCheckViolation)
+	return ok
+}
+
+func constraintMessage(kind, constraint string, columns []string, err error) string {
+	msg := "gormqs: " + kind
+	if constraint != "" {
+		msg += " on " + constraint
+	}
+	if len(columns) > 0 {
+		msg += " (" + strings.Join(columns, ", ") + ")"
+	}
+	if err != nil {
+		msg += ": " + err.Error()
+	}
+	return msg
+}
+
+// kindError keep original message, match both kind and err
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// errorKind of a driver error
+type errorKind int
+
+const (
+	errorKindUnknown errorKind = iota
+	errorKindDuplicate
+	errorKindForeignKey
+	errorKindCheck
+)
+
+// driverError is the dialect independent form of a driver error
+type driverError struct {
+	kind       errorKind
+	constraint string
+	columns    []string
+}
+
+// translateError map gorm and driver errors of sqlite, postgres and mysql into gormqs errors
+func translateError(err error) error {
+	if err == nil || isTranslated(err) {
+		return err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, sql.ErrNoRows) {
+		return &kindError{kind: ErrNotFound, err: err}
+	}
+
+	if errors.Is(err, gorm.ErrMissingWhereClause) {
+		return fmt.Errorf("%w: %w", ErrMissingWhere, err)
+	}
+
+	info := inspectError(err)
+	switch info.kind {
+	case errorKindDuplicate:
+		return &ErrDuplicate{Constraint: info.constraint, Columns: info.columns, Err: err}
+	case errorKindForeignKey:
+		return &ErrForeignKey{Constraint: info.constraint, Err: err}
+	case errorKindCheck:
+		return &ErrCheckViolation{Constraint: info.constraint, Err: err}
+	}
+
+	return err
+}
+
+func isTranslated(err error) bool {
+	var kind *kindError
+	return errors.As(err, &kind) ||
+		errors.Is(err, ErrMissingWhere) ||
+		errors.Is(err, &ErrDuplicate{}) ||
+		errors.Is(err, &ErrForeignKey{}) ||
+		errors.Is(err, &ErrCheckViolation{})
+}
+
+// inspectError walk error chain and inspect driver error without importing drivers
+func inspectError(err error) driverError {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch {
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			return driverError{kind: errorKindDuplicate}
+		case errors.Is(err, gorm.ErrForeignKeyViolated):
+			return driverError{kind: errorKindForeignKey}
+		case errors.Is(err, gorm.ErrCheckConstraintViolated):
+			return driverError{kind: errorKindCheck}
+		}
+
+		if info, ok := inspectPostgresError(err); ok {
+			return info
+		}
+		if info, ok := inspectMysqlError(err); ok {
+			return info
+		}
+		if info, ok := inspectSqliteError(err); ok {
+			return info
+		}
+	}
+
+	return driverError{}
+}
+
+var postgresKeyColumns = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// inspectPostgresError support pgconn.PgError and pq.Error
+func inspectPostgresError(err error) (driverError, bool) {
+	code, ok := postgresCode(err)
+	if !ok {
+		return driverError{}, false
+	}
+
+	info := driverError{kind: postgresErrorKind(code)}
+	if info.kind == errorKindUnknown {
+		// keep walking the error chain
+		return info, false
+	}
+
+	info.constraint, _ = structString(err, "ConstraintName", "Constraint")
+	if detail, _ := structString(err, "Detail"); detail != "" {
+		if match := postgresKeyColumns.FindStringSubmatch(detail); match != nil {
+			info.columns = splitColumns(match[1])
+		}
+	}
+
+	return info, true
+}
+
+// postgresCode SQLSTATE of pgconn.PgError or pq.Error
+func postgresCode(err error) (string, bool) {
+	if state, ok := err.(interface{ SQLState() string }); ok {
+		return state.SQLState(), true
+	}
+	if code, ok := structString(err, "Code"); ok && len(code) == 5 {
+		return code, true
+	}
+	return "", false
+}
+
+func postgresErrorKind(code string) errorKind {
+	switch code {
+	case "23505":
+		return errorKindDuplicate
+	case "23503":
+		return errorKindForeignKey
+	case "23514":
+		return errorKindCheck
+	}
+	return errorKindUnknown
+}
+
+var (
+	mysqlDuplicateKey    = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlForeignKey      = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+	mysqlCheckConstraint = regexp.MustCompile(`constraint '([^']+)'`)
+)
+
+// inspectMysqlError support mysql.MySQLError
+func inspectMysqlError(err error) (driverError, bool) {
+	number, ok := structUint(err, "Number")
+	if !ok {
+		return driverError{}, false
+	}
+
+	message, _ := structString(err, "Message")
+	switch number {
+	case 1062, 1586:
+		info := driverError{kind: errorKindDuplicate}
+		if match := mysqlDuplicateKey.FindStringSubmatch(message); match != nil {
+			// mysql 8 prefix key with table name
+			key := match[1]
+			if i := strings.LastIndex(key, "."); i >= 0 {
+				key = key[i+1:]
+			}
+			info.constraint = key
+		}
+		return info, true
+
+	case 1216, 1217, 1451, 1452:
+		info := driverError{kind: errorKindForeignKey}
+		if match := mysqlForeignKey.FindStringSubmatch(message); match != nil {
+			info.constraint = match[1]
+		}
+		return info, true
+
+	case 3819:
+		info := driverError{kind: errorKindCheck}
+		if match := mysqlCheckConstraint.FindStringSubmatch(message); match != nil {
+			info.constraint = match[1]
+		}
+		return info, true
+	}
+
+	// unknown number, keep walking the error chain
+	return driverError{}, false
+}
+
+// sqlite result codes
+const (
+	sqliteBusy   = 5
+	sqliteLocked = 6
+
+	sqliteConstraintCheck      = 275
+	sqliteConstraintForeignKey = 787
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+)
+
+var (
+	sqliteUniqueColumns = regexp.MustCompile(`UNIQUE constraint failed: ([^(]+)`)
+	sqliteCheckName     = regexp.MustCompile(`CHECK constraint failed: ([^(]+)`)
+)
+
+// inspectSqliteError support mattn/go-sqlite3 and modernc.org/sqlite
+func inspectSqliteError(err error) (driverError, bool) {
+	code, ok := sqliteCode(err)
+	if !ok {
+		return driverError{}, false
+	}
+
+	message := err.Error()
+	switch code {
+	case sqliteConstraintUnique, sqliteConstraintPrimaryKey:
+		info := driverError{kind: errorKindDuplicate}
+		if match := sqliteUniqueColumns.FindStringSubmatch(message); match != nil {
+			for _, column := range splitColumns(match[1]) {
+				// strip table name
+				if i := strings.LastIndex(column, "."); i >= 0 {
+					column = column[i+1:]
+				}
+				info.columns = append(info.columns, column)
+			}
+		}
+		return info, true
+
+	case sqliteConstraintForeignKey:
+		return driverError{kind: errorKindForeignKey}, true
+
+	case sqliteConstraintCheck:
+		info := driverError{kind: errorKindCheck}
+		if match := sqliteCheckName.FindStringSubmatch(message); match != nil {
+			info.constraint = strings.TrimSpace(match[1])
+		}
+		return info, true
+	}
+
+	// unknown code, keep walking the error chain
+	return driverError{}, false
+}
+
+/*
+IsRetryable report whether err is a serialization failure, deadlock or lock timeout, the whole transaction can be retried
+
+	postgres: 40001 serialization_failure, 40P01 deadlock_detected
+	mysql:    1213 deadlock, 1205 lock wait timeout
+	sqlite:   SQLITE_BUSY, SQLITE_LOCKED
+*/
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if code, ok := postgresCode(err); ok && (code == "40001" || code == "40P01") {
+		return true
+	}
+
+	if number, ok := structUint(err, "Number"); ok && (number == 1213 || number == 1205) {
+		return true
+	}
+
+	if code, ok := sqliteCode(err); ok {
+		// primary result code of extended code
+		switch code & 0xff {
+		case sqliteBusy, sqliteLocked:
+			return true
+		}
+	}
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		return IsRetryable(wrapped.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, err := range wrapped.Unwrap() {
+			if IsRetryable(err) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sqliteCode extended result code of mattn/go-sqlite3 or modernc.org/sqlite error
+func sqliteCode(err error) (int64, bool) {
+	if code, ok := structInt(err, "ExtendedCode"); ok {
+		return code, true
+	}
+	if coder, ok := err.(interface{ Code() int }); ok {
+		return int64(coder.Code()), true
+	}
+	return 0, false
+}
+
+func splitColumns(columns string) []string {
+	parts := strings.Split(columns, ",")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), "\"`")
+	}
+	return parts
+}
+
+// structField return first exported field of struct err in names
+func structField(err error, names ...string) (reflect.Value, bool) {
+	rv := reflect.ValueOf(err)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}, false
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	for _, name := range names {
+		if field, ok := rv.Type().FieldByName(name); ok && field.IsExported() {
+			return rv.FieldByIndex(field.Index), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func structString(err error, names ...string) (string, bool) {
+	field, ok := structField(err, names...)
+	if !ok || field.Kind() != reflect.String {
+		return "", false
+	}
+	return field.String(), true
+}
+
+func structInt(err error, names ...string) (int64, bool) {
+	field, ok := structField(err, names...)
+	if !ok {
+		return 0, false
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), true
+	}
+	return 0, false
+}
+
+func structUint(err error, names ...string) (uint64, bool) {
+	field, ok := structField(err, names...)
+	if !ok {
+		return 0, false
+	}
+
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field.Uint(), true
+	}
+	return 0, false
+}
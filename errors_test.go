@@ -0,0 +1,227 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+)
+
+// driver shaped errors, the same exported fields as the real drivers
+
+type fakePgError struct {
+	Code           string
+	Detail         string
+	ConstraintName string
+}
+
+func (e *fakePgError) Error() string { return "pg: " + e.Code }
+
+func (e *fakePgError) SQLState() string { return e.Code }
+
+type fakeMySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *fakeMySQLError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+type fakeSqliteError struct {
+	Code         int
+	ExtendedCode int
+	msg          string
+}
+
+func (e fakeSqliteError) Error() string { return e.msg }
+
+// stateError error with an unrelated SQLSTATE wrapping a driver error
+type stateError struct {
+	state string
+	err   error
+}
+
+func (e *stateError) Error() string { return e.err.Error() }
+
+func (e *stateError) SQLState() string { return e.state }
+
+func (e *stateError) Unwrap() error { return e.err }
+
+// extendedCodeError error with an unrelated sqlite code wrapping a driver error
+type extendedCodeError struct {
+	ExtendedCode int
+	err          error
+}
+
+func (e extendedCodeError) Error() string { return e.err.Error() }
+
+func (e extendedCodeError) Unwrap() error { return e.err }
+
+// numberedError unrelated error with a Number field wrapping a driver error
+type numberedError struct {
+	Number uint
+	err    error
+}
+
+func (e *numberedError) Error() string { return e.err.Error() }
+
+func (e *numberedError) Unwrap() error { return e.err }
+
+func TestTranslateError(t *testing.T) {
+	var (
+		duplicate  = &gormqs.ErrDuplicate{}
+		foreignKey = &gormqs.ErrForeignKey{}
+		check      = &gormqs.ErrCheckViolation{}
+	)
+
+	tests := []struct {
+		name       string
+		err        error
+		kind       error
+		constraint string
+		columns    []string
+	}{
+		{
+			name:       "postgres unique",
+			err:        &fakePgError{Code: "23505", Detail: "Key (username, email)=(foxie, a@b.c) already exists.", ConstraintName: "users_username_key"},
+			kind:       duplicate,
+			constraint: "users_username_key",
+			columns:    []string{"username", "email"},
+		},
+		{
+			name:       "postgres foreign key",
+			err:        &fakePgError{Code: "23503", ConstraintName: "orders_user_id_fkey"},
+			kind:       foreignKey,
+			constraint: "orders_user_id_fkey",
+		},
+		{
+			name:       "postgres check",
+			err:        &fakePgError{Code: "23514", ConstraintName: "balance_positive"},
+			kind:       check,
+			constraint: "balance_positive",
+		},
+		{
+			name:       "mysql duplicate",
+			err:        &fakeMySQLError{Number: 1062, Message: "Duplicate entry 'foxie' for key 'users.idx_users_username'"},
+			kind:       duplicate,
+			constraint: "idx_users_username",
+		},
+		{
+			name:       "mysql foreign key",
+			err:        &fakeMySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`orders`, CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			kind:       foreignKey,
+			constraint: "fk_orders_user",
+		},
+		{
+			name:       "mysql check",
+			err:        &fakeMySQLError{Number: 3819, Message: "Check constraint 'balance_positive' is violated."},
+			kind:       check,
+			constraint: "balance_positive",
+		},
+		{
+			name:    "sqlite unique",
+			err:     fakeSqliteError{Code: 19, ExtendedCode: 2067, msg: "UNIQUE constraint failed: users.username, users.email"},
+			kind:    duplicate,
+			columns: []string{"username", "email"},
+		},
+		{
+			name: "sqlite foreign key",
+			err:  fakeSqliteError{Code: 19, ExtendedCode: 787, msg: "FOREIGN KEY constraint failed"},
+			kind: foreignKey,
+		},
+		{
+			name:       "sqlite check",
+			err:        fakeSqliteError{Code: 19, ExtendedCode: 275, msg: "CHECK constraint failed: balance_positive"},
+			kind:       check,
+			constraint: "balance_positive",
+		},
+		{
+			name:       "unknown number wrapping sqlite",
+			err:        &numberedError{Number: 42, err: fakeSqliteError{Code: 19, ExtendedCode: 275, msg: "CHECK constraint failed: balance_positive"}},
+			kind:       check,
+			constraint: "balance_positive",
+		},
+		{
+			name: "gorm translated",
+			err:  fmt.Errorf("create user: %w", gorm.ErrDuplicatedKey),
+			kind: duplicate,
+		},
+		{
+			name: "not found",
+			err:  gorm.ErrRecordNotFound,
+			kind: gormqs.ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// error of the base db is returned by every query
+			qs, _ := newTestQueries[testUser](t)
+			qs.db.AddError(test.err)
+
+			err := qs.CreateOne(context.Background(), &testUser{})
+			if !errors.Is(err, test.kind) {
+				t.Fatalf("got %v, want %T", err, test.kind)
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Errorf("original error must be kept, got %v", err)
+			}
+
+			var (
+				constraint string
+				columns    []string
+				dup        *gormqs.ErrDuplicate
+				fk         *gormqs.ErrForeignKey
+				chk        *gormqs.ErrCheckViolation
+			)
+			switch {
+			case errors.As(err, &dup):
+				constraint, columns = dup.Constraint, dup.Columns
+			case errors.As(err, &fk):
+				constraint = fk.Constraint
+			case errors.As(err, &chk):
+				constraint = chk.Constraint
+			}
+
+			if constraint != test.constraint {
+				t.Errorf("constraint got %q, want %q", constraint, test.constraint)
+			}
+			if !slices.Equal(columns, test.columns) {
+				t.Errorf("columns got %v, want %v", columns, test.columns)
+			}
+		})
+	}
+}
+
+func TestTranslateWrappedDriverError(t *testing.T) {
+	// real unique violation of sqlite driver
+	sqliteQueries := newSqliteQueries(t, &testUser{ID: 1, Username: "foxie"})
+	driverErr := sqliteQueries.db.Exec("INSERT INTO users (id, username, balance) VALUES (1, 'foxie', 0)").Error
+	if driverErr == nil {
+		t.Fatal("expect unique violation")
+	}
+
+	wrappers := map[string]error{
+		"postgres": &stateError{state: "XX000", err: driverErr},
+		"sqlite":   extendedCodeError{ExtendedCode: 1, err: driverErr},
+	}
+
+	for name, wrapped := range wrappers {
+		qs, _ := newTestQueries[testUser](t)
+		qs.db.AddError(wrapped)
+
+		err := qs.CreateOne(context.Background(), &testUser{})
+		var dup *gormqs.ErrDuplicate
+		if !errors.As(err, &dup) {
+			t.Errorf("%s: got %v, want ErrDuplicate", name, err)
+			continue
+		}
+		if !slices.Equal(dup.Columns, []string{"id"}) {
+			t.Errorf("%s: columns got %v, want [id]", name, dup.Columns)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"sync"
 
 	"github.com/foxie-io/gormqs"
+	"github.com/foxie-io/gormqs/pagination"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -69,38 +70,42 @@ func main() {
 	// list and count : `curl 'localhost:8080/users/page'`
 	// count only : `curl 'localhost:8080/users/page?select=count'`
 	// list only : `curl 'localhost:8080/users/page?select=list'`
+	// list and has next without count : `curl 'localhost:8080/users/page?select=list,hasNext'`
 
 	mux.HandleFunc("GET /users/page", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		pageParam := dto.NewPageParam[[]dto.BaseUser](r)
+		page, err := pagination.Parse[dto.BaseUser](r.URL.Query(), pagination.WithMaxSize(50))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-		// pageParam implement gormqs.ManyWithCountResulter
+		// page implement gormqs.ListOrCountResulter
 		// for pagination, sometimes we don't need to count, becuase count cost alot of performance
-		if err := user_qs.GetListTo(ctx, pageParam, pageParam.DBOption()); err != nil {
+		// without count, hasNext is detected by fetching one more row
+		if err := user_qs.GetListTo(ctx, page); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		customResulter := pageParam.Result()
-		if customResulter.Data != nil {
-			hasNext := pageParam.HasNext(len(*customResulter.Data))
-			customResulter.HasNext = &hasNext
+		selection, err := gormqs.ParseSelection(r.URL.Query().Get("select"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-
-		selects := r.URL.Query().Get("select")
-		if selects == "" {
-			selects = "count,list"
+		if selection == 0 {
+			selection = gormqs.SelectList | gormqs.SelectCount
 		}
 
-		listResuler := gormqs.NewListResulter[dto.BaseUser](selects)
+		listResuler := gormqs.NewListResulter[dto.BaseUser](selection)
 		if err := user_qs.GetListTo(ctx, listResuler, gormqs.LimitAndOffset(3, 0)); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
 		responseJson(w, map[string]any{
-			"listResuler":    listResuler,
-			"customResulter": customResulter,
+			"listResuler": listResuler,
+			"page":        page,
 		})
 	})
 
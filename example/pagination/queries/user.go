@@ -1,7 +1,6 @@
 package queries
 
 import (
-	"context"
 	"example/pagination/models"
 
 	"github.com/foxie-io/gormqs"
@@ -15,20 +14,10 @@ var (
 
 type (
 	UserQueries struct {
-		gormqs.Queries[models.User, *UserQueries]
-		db    *gorm.DB
-		model models.User
+		gormqs.DefaultQueries[models.User]
 	}
 )
 
-// provider db instance for gormqs.Queries to use
-func (qs *UserQueries) DBInstance(ctx context.Context) *gorm.DB {
-	db := gormqs.ContextValue(ctx, qs.db)
-	return db.WithContext(ctx).Table(qs.model.TableName()).Model(qs.model)
-}
-
 func NewUserQueries(db *gorm.DB) *UserQueries {
-	qs := &UserQueries{db: db}
-	qs.Queries = gormqs.NewQueries[models.User](qs)
-	return qs
+	return &UserQueries{gormqs.New[models.User](db)}
 }
@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/foxie-io/gormqs"
+	"github.com/foxie-io/gormqs/outbox"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -28,6 +29,7 @@ var (
 	item_qs      *queries.ItemQueries
 	order_qs     *queries.OrderQueries
 	orderItem_qs *queries.OrderItemQueries
+	outbox_qs    *outbox.Queries
 )
 
 func getDB() *gorm.DB {
@@ -42,6 +44,7 @@ func getDB() *gorm.DB {
 		item_qs = queries.NewItemQueries(db)
 		order_qs = queries.NewOrderQueries(db)
 		orderItem_qs = queries.NewOrderItemQueries(db)
+		outbox_qs = outbox.NewQueries(db)
 	})
 	return db
 }
@@ -88,10 +91,8 @@ func createUserOrder(ctx context.Context, userId uint, orderItems []*models.Orde
 	}
 
 	// tx2
-	err = getDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// wrap tx into ctx, so queries can use it as tx instance
-		ctx := gormqs.WrapContext(tx)
-
+	err = gormqs.Transaction(ctx, getDB(), func(ctx context.Context) error {
+		// ctx carries tx2, so queries use it as tx instance
 		// create an order
 		if err := order_qs.CreateOne(ctx, order); err != nil {
 			return err
@@ -106,6 +107,16 @@ func createUserOrder(ctx context.Context, userId uint, orderItems []*models.Orde
 			return err
 		}
 
+		// event is committed or rolled back with the order
+		if _, err := outbox_qs.Enqueue(ctx, "order.created", fmt.Sprint(order.ID), order); err != nil {
+			return err
+		}
+
+		// runs only once tx2 is committed
+		gormqs.OnCommit(ctx, func(ctx context.Context) {
+			log.Printf("order %d created, notify user %d", order.ID, userId)
+		})
+
 		// commit blocked balance = success
 		// will use tx2 instance because of tx2Ctx
 		_, err = user_qs.CommitBlockedBalance(ctx, user.ID, order.PayAmount)
@@ -130,7 +141,7 @@ func createUserOrder(ctx context.Context, userId uint, orderItems []*models.Orde
 func main() {
 	ctx := context.Background()
 	db := getDB()
-	err := db.AutoMigrate(&models.User{}, &models.Item{}, &models.Order{}, &models.OrderItem{})
+	err := db.AutoMigrate(&models.User{}, &models.Item{}, &models.Order{}, &models.OrderItem{}, &outbox.Message{})
 	mustNotErr(err)
 
 	user1, err := createUser(ctx, 1)
@@ -158,6 +169,21 @@ func main() {
 
 	log.Println("order with details:")
 	printJson(orderWithDetails)
+
+	ordersPerUser, err := order_qs.CountPerUser(ctx)
+	mustNotErr(err)
+
+	log.Println("orders per user:")
+	printJson(ordersPerUser)
+
+	// publish events written by transactions, usually go relay.Run(ctx)
+	relay := outbox.NewRelay(outbox_qs, outbox.PublisherFunc(func(ctx context.Context, msg *outbox.Message) error {
+		log.Printf("publish %s key=%s payload=%s", msg.Topic, msg.Key, msg.Payload)
+		return nil
+	}))
+	published, err := relay.ProcessBatch(ctx)
+	mustNotErr(err)
+	log.Printf("published %d events", published)
 }
 
 func printJson(v interface{}) {
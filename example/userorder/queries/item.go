@@ -1,7 +1,6 @@
 package queries
 
 import (
-	"context"
 	"example/userorder/models"
 
 	"github.com/foxie-io/gormqs"
@@ -15,19 +14,10 @@ var (
 
 type (
 	ItemQueries struct {
-		gormqs.Queries[models.Item, *ItemQueries]
-		db    *gorm.DB
-		model models.Item
+		gormqs.DefaultQueries[models.Item]
 	}
 )
 
-func (qr ItemQueries) DBInstance(ctx context.Context) *gorm.DB {
-	db := gormqs.ContextValue(ctx, qr.db)
-	return db.WithContext(ctx).Model(qr.model)
-}
-
 func NewItemQueries(db *gorm.DB) *ItemQueries {
-	qs := &ItemQueries{db: db}
-	qs.Queries = gormqs.NewQueries[models.Item](qs)
-	return qs
+	return &ItemQueries{gormqs.New[models.Item](db)}
 }
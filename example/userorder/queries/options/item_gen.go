@@ -0,0 +1,55 @@
+// Code generated by gormqs-gen. DO NOT EDIT.
+
+package qopt
+
+import (
+	"fmt"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+)
+
+type ItemColumn string
+
+type ItemSchema struct {
+	ID        ItemColumn
+	CreatedAt ItemColumn
+	UpdatedAt ItemColumn
+	Product   ItemColumn
+	Quantity  ItemColumn
+	Price     ItemColumn
+}
+
+var ITEM = ItemSchema{
+	ID:        "id",
+	CreatedAt: "created_at",
+	UpdatedAt: "updated_at",
+	Product:   "product",
+	Quantity:  "quantity",
+	Price:     "price",
+}
+
+func (s ItemSchema) Where(col ItemColumn, operation, value any) gormqs.Option {
+	return func(db *gorm.DB) *gorm.DB {
+		query := fmt.Sprintf("%s %s ?", gormqs.WithTable(string(col), db), operation)
+		return db.Where(query, value)
+	}
+}
+
+func (s ItemSchema) SelectAll(db *gorm.DB) *gorm.DB {
+	return db.Select("*")
+}
+
+func (s ItemSchema) Select(cols ...ItemColumn) gormqs.Option {
+	return func(db *gorm.DB) *gorm.DB {
+		columns := make([]string, len(cols))
+		for i, col := range cols {
+			columns[i] = gormqs.WithTable(string(col), db)
+		}
+		return db.Select(columns)
+	}
+}
+
+func (s ItemSchema) WhereID(id uint) gormqs.Option {
+	return s.Where(s.ID, "=", id)
+}
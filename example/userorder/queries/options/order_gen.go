@@ -1,3 +1,5 @@
+// Code generated by gormqs-gen. DO NOT EDIT.
+
 package qopt
 
 import (
@@ -10,19 +12,21 @@ import (
 type OrderColumn string
 
 type OrderSchema struct {
-	ID        OrderColumn
-	CreatedAt OrderColumn
-	UpdatedAt OrderColumn
-	Discount  OrderColumn
-	UserID    OrderColumn
+	ID             OrderColumn
+	CreatedAt      OrderColumn
+	UpdatedAt      OrderColumn
+	PayAmount      OrderColumn
+	DiscountAmount OrderColumn
+	UserID         OrderColumn
 }
 
 var ORDER = OrderSchema{
-	ID:        "id",
-	CreatedAt: "created_at",
-	UpdatedAt: "updated_at",
-	Discount:  "discount",
-	UserID:    "user_id",
+	ID:             "id",
+	CreatedAt:      "created_at",
+	UpdatedAt:      "updated_at",
+	PayAmount:      "pay_amount",
+	DiscountAmount: "discount_amount",
+	UserID:         "user_id",
 }
 
 func (s OrderSchema) Where(col OrderColumn, operation, value any) gormqs.Option {
@@ -32,13 +36,16 @@ func (s OrderSchema) Where(col OrderColumn, operation, value any) gormqs.Option
 	}
 }
 
+func (s OrderSchema) SelectAll(db *gorm.DB) *gorm.DB {
+	return db.Select("*")
+}
+
 func (s OrderSchema) Select(cols ...OrderColumn) gormqs.Option {
 	return func(db *gorm.DB) *gorm.DB {
 		columns := make([]string, len(cols))
 		for i, col := range cols {
 			columns[i] = gormqs.WithTable(string(col), db)
 		}
-
 		return db.Select(columns)
 	}
 }
@@ -47,14 +54,14 @@ func (s OrderSchema) WhereID(id uint) gormqs.Option {
 	return s.Where(s.ID, "=", id)
 }
 
-func (s OrderSchema) PreloadOrderItems() gormqs.Option {
+func (s OrderSchema) PreloadOrderItems(args ...any) gormqs.Option {
 	return func(db *gorm.DB) *gorm.DB {
-		return db.Preload("OrderItems")
+		return db.Preload("OrderItems", args...)
 	}
 }
 
-func (s OrderSchema) PreloadUser() gormqs.Option {
+func (s OrderSchema) PreloadUser(args ...any) gormqs.Option {
 	return func(db *gorm.DB) *gorm.DB {
-		return db.Preload("User")
+		return db.Preload("User", args...)
 	}
 }
@@ -0,0 +1,4 @@
+// Package qopt typed schema options of models, generated by gormqs-gen
+package qopt
+
+//go:generate go run github.com/foxie-io/gormqs/cmd/gormqs-gen -models ../../models -out . -pkg qopt
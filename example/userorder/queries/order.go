@@ -16,21 +16,12 @@ var (
 
 type (
 	OrderQueries struct {
-		gormqs.Queries[models.Order, *OrderQueries]
-		db    *gorm.DB
-		model models.Order
+		gormqs.DefaultQueries[models.Order]
 	}
 )
 
-func (qs *OrderQueries) DBInstance(ctx context.Context) *gorm.DB {
-	db := gormqs.ContextValue(ctx, qs.db)
-	return db.WithContext(ctx).Table(qs.model.TableName()).Model(qs.model)
-}
-
 func NewOrderQueries(db *gorm.DB) *OrderQueries {
-	qs := &OrderQueries{db: db}
-	qs.Queries = gormqs.NewQueries[models.Order](qs)
-	return qs
+	return &OrderQueries{gormqs.New[models.Order](db)}
 }
 
 /*
@@ -50,3 +41,8 @@ func (qs *OrderQueries) GetOneWithDetails(ctx context.Context, orderID uint) (*m
 		qopt.ORDER.PreloadUser(),
 	)
 }
+
+// CountPerUser number of orders of each user
+func (qs *OrderQueries) CountPerUser(ctx context.Context, opts ...gormqs.Option) (map[uint]int64, error) {
+	return gormqs.GroupMap[uint, int64](ctx, qs, string(qopt.ORDER.UserID), gormqs.CountAs("orders"), opts...)
+}
@@ -1,7 +1,6 @@
 package queries
 
 import (
-	"context"
 	"example/userorder/models"
 
 	"github.com/foxie-io/gormqs"
@@ -15,19 +14,10 @@ var (
 
 type (
 	OrderItemQueries struct {
-		gormqs.Queries[models.OrderItem, *OrderItemQueries]
-		db    *gorm.DB
-		model models.OrderItem
+		gormqs.DefaultQueries[models.OrderItem]
 	}
 )
 
-func (qs OrderItemQueries) DBInstance(ctx context.Context) *gorm.DB {
-	db := gormqs.ContextValue(ctx, qs.db)
-	return db.WithContext(ctx).Model(qs.model)
-}
-
 func NewOrderItemQueries(db *gorm.DB) *OrderItemQueries {
-	qs := &OrderItemQueries{db: db}
-	qs.Queries = gormqs.NewQueries[models.OrderItem](qs)
-	return qs
+	return &OrderItemQueries{gormqs.New[models.OrderItem](db)}
 }
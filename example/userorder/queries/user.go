@@ -16,28 +16,17 @@ var (
 
 type (
 	UserQueries struct {
-		gormqs.Queries[models.User, *UserQueries]
-		db    *gorm.DB
-		model models.User
+		gormqs.DefaultQueries[models.User]
 	}
 )
 
-// provider db instance for gormqs.Queries to use
-func (qs *UserQueries) DBInstance(ctx context.Context) *gorm.DB {
-	db := gormqs.ContextValue(ctx, qs.db)
-	return db.WithContext(ctx).Table(qs.model.TableName()).Model(qs.model)
-}
-
 func NewUserQueries(db *gorm.DB) *UserQueries {
-	qs := &UserQueries{db: db}
-	qs.Queries = gormqs.NewQueries[models.User](qs)
-	return qs
+	return &UserQueries{gormqs.New[models.User](db)}
 }
 
 func (qs *UserQueries) LockForUpdate(ctx context.Context, userId uint, updateUser func(u models.User) models.User, updateColumns ...qopt.UserColumn) (returnUser *models.User, returnErr error) {
-	returnErr = qs.DBInstance(ctx).Transaction(func(tx *gorm.DB) error {
-		ctx := gormqs.WrapContext(tx)
-
+	// retry on deadlock, savepoint when called inside another transaction
+	returnErr = gormqs.RetryTransaction(ctx, qs.DBInstance(ctx), func(ctx context.Context) error {
 		user, err := qs.GetOne(ctx, gormqs.LockForUpdate(), qopt.USER.WhereID(userId))
 		if err != nil {
 			return err
@@ -2,10 +2,14 @@ module github.com/foxie-io/gormqs
 
 go 1.25.2
 
-require gorm.io/gorm v1.31.1
+require (
+	gorm.io/driver/sqlite v1.6.0
+	gorm.io/gorm v1.31.1
+)
 
 require (
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
 	golang.org/x/text v0.20.0 // indirect
 )
@@ -3,7 +3,11 @@ package gormqs
 import (
 	"context"
 	"errors"
-	"strings"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -73,30 +77,62 @@ type ListOrCountResulter interface {
 	QsCount() *int64
 }
 
+// ListOptioner optional interface of ListOrCountResulter, option is applied last to the list query
+type ListOptioner interface {
+	QsListOption() Option
+}
+
+// ListFinalizer optional interface of ListOrCountResulter, called after list and count queries success
+type ListFinalizer interface {
+	QsAfterList() error
+}
+
+// ListCapper optional interface of ListOrCountResulter, flag of QsCapped is set when CappedCount reach its cap
+type ListCapper interface {
+	QsCapped() *bool
+}
+
 var (
 	// ListResulter implement interface ListOrCountResulter
 	_ ListOrCountResulter = (*ListResulter[Model])(nil)
+	_ ListOptioner        = (*ListResulter[Model])(nil)
+	_ ListFinalizer       = (*ListResulter[Model])(nil)
+	_ ListCapper          = (*ListResulter[Model])(nil)
 )
 
 type ListResulter[T Model] struct {
-	selects string
-	List    *[]*T  `json:"list,omitempty"`
-	Count   *int64 `json:"count,omitempty"`
+	selection Selection
+	limit     int    // -1 without LIMIT
+	List      *[]*T  `json:"list,omitempty"`
+	Count     *int64 `json:"count,omitempty"`
+	Capped    bool   `json:"capped,omitempty"` // more rows than Count exist, see CappedCount
+	HasNext   *bool  `json:"hasNext,omitempty"`
 }
 
 /*
 ListResulter
 
-selects := "list,count" | "list" | "count"
+	NewListResulter[models.User](SelectList | SelectCount)
+
+	// no COUNT, fetch limit+1 rows and trim
+	NewListResulter[models.User](SelectList | SelectHasNext)
+
+	sel, err := ParseSelection(r.URL.Query().Get("select"))
+	NewListResulter[models.User](sel)
 */
-func NewListResulter[T Model](selects string) *ListResulter[T] {
+func NewListResulter[T Model](selection Selection) *ListResulter[T] {
+	// has next need rows
+	if selection.Has(SelectHasNext) {
+		selection |= SelectList
+	}
+
 	return &ListResulter[T]{
-		selects: selects,
+		selection: selection,
 	}
 }
 
 func (r *ListResulter[T]) QsList() any {
-	if strings.Contains(r.selects, "list") {
+	if r.selection.Has(SelectList) {
 		r.List = new([]*T)
 		return r.List
 	}
@@ -104,13 +140,49 @@ func (r *ListResulter[T]) QsList() any {
 }
 
 func (r *ListResulter[T]) QsCount() *int64 {
-	if strings.Contains(r.selects, "count") {
+	if r.selection.Has(SelectCount) {
 		r.Count = new(int64)
 		return r.Count
 	}
 	return nil
 }
 
+func (r *ListResulter[T]) QsCapped() *bool {
+	r.Capped = false
+	return &r.Capped
+}
+
+// QsListOption fetch one more row than LIMIT when has next is selected
+func (r *ListResulter[T]) QsListOption() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		r.limit = -1
+		if !r.selection.Has(SelectHasNext) {
+			return db
+		}
+
+		limit, ok := db.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
+		if !ok || limit.Limit == nil || *limit.Limit < 0 {
+			return db
+		}
+
+		r.limit = *limit.Limit
+		return db.Limit(r.limit + 1)
+	}
+}
+
+func (r *ListResulter[T]) QsAfterList() error {
+	if !r.selection.Has(SelectHasNext) || r.List == nil {
+		return nil
+	}
+
+	hasNext := r.limit >= 0 && len(*r.List) > r.limit
+	if hasNext {
+		*r.List = (*r.List)[:r.limit]
+	}
+	r.HasNext = &hasNext
+	return nil
+}
+
 type Querier interface {
 	// instance use to build query
 	DBInstance(ctx context.Context) *gorm.DB
@@ -119,11 +191,51 @@ type Querier interface {
 type Queries[M Model, Q any] interface {
 	// get the querier instance
 	Querier() Q
+	// instance of querier, so Queries can be used as Querier
+	DBInstance(ctx context.Context) *gorm.DB
 	CreateOne(ctx context.Context, record *M) error
 	CreateMany(ctx context.Context, record *[]*M) error
+
+	/*UpsertOne insert or handle conflict with action
+
+	// INSERT INTO "users" ... ON CONFLICT ("username") DO UPDATE SET "balance"="excluded"."balance"
+	affectedRow, err := qs.UpsertOne(ctx, &user, OnColumns("username"), DoUpdateColumns("balance"))
+
+	// INSERT INTO "users" ... ON CONFLICT DO NOTHING
+	affectedRow, err := qs.UpsertOne(ctx, &user, nil, DoNothing())
+
+	nil action is DoNothing, mysql report 2 affected rows for each updated row
+	*/
+	UpsertOne(ctx context.Context, record *M, target ConflictTarget, action ConflictAction, opts ...Option) (affectedRow int64, err error)
+
+	// UpsertMany same as UpsertOne for many records
+	UpsertMany(ctx context.Context, records *[]*M, target ConflictTarget, action ConflictAction, opts ...Option) (affectedRow int64, err error)
+
 	GetOne(ctx context.Context, opts ...Option) (result *M, err error)
 	GetMany(ctx context.Context, opts ...Option) (result []*M, err error)
 
+	/*Iterate stream rows one by one without loading the whole result, preload is not supported
+
+	for user, err := range qs.Iterate(ctx, Where("balance > ?", 0)) {
+		if err != nil {
+			return err
+		}
+		// break early is safe, rows will be closed
+	}
+	*/
+	Iterate(ctx context.Context, opts ...Option) iter.Seq2[*M, error]
+
+	/*IterateBatches stream rows in batches ordered by primary key, see gorm.DB.FindInBatches.
+	ErrInvalidBatchSize is yielded when batchSize is not positive
+
+	for users, err := range qs.IterateBatches(ctx, 500) {
+		if err != nil {
+			return err
+		}
+	}
+	*/
+	IterateBatches(ctx context.Context, batchSize int, opts ...Option) iter.Seq2[[]*M, error]
+
 	/*
 		Update
 
@@ -139,6 +251,9 @@ type Queries[M Model, Q any] interface {
 			)
 			user.money = 100
 			qs.Update(ctx, &userNewValue, WithModel(&updatedUser) // after update return value update to user
+
+		model implement Versioned is updated with optimistic locking, ErrStaleRecord is returned when no row match the version
+		without WHERE clause ErrMissingWhere is returned, same as UpdateWithExpr and Delete, use AllowFullTable() to update all rows
 	*/
 	Update(ctx context.Context, record *M, opt Option, opts ...Option) (affectedRow int64, err error)
 
@@ -177,6 +292,9 @@ type Queries[M Model, Q any] interface {
 	/*Delete need atleast one option
 
 	count, err := qs.Delete(ctx, Where(id > 0)) // SQL: DELETE FROM "users" WHERE "users"."id" > 0
+
+	without WHERE clause ErrMissingWhere is returned, use AllowFullTable() to delete all rows
+	count, err := qs.Delete(ctx, AllowFullTable()) // SQL: DELETE FROM "users"
 	*/
 	Delete(ctx context.Context, opt Option, opts ...Option) (affectedRow int64, err error)
 
@@ -226,18 +344,25 @@ type Queries[M Model, Q any] interface {
 
 	/* get many ony, count only or both
 
+	resulter := NewListResulter[User](SelectList | SelectCount) // list and count
+	err := qs.GetListTo(ctx, resulter)
 
-	var resulter NewManyWithCountResulter[User]("many,count") // list and count
-	err := qs.GetList(ctx, resulter)
+	resulter := NewListResulter[User](SelectCount) // count only
+	err := qs.GetListTo(ctx, resulter)
 
+	resulter := NewListResulter[User](SelectList) // list only
+	err := qs.GetListTo(ctx, resulter)
+	*/
+	GetListTo(ctx context.Context, listResulter ListOrCountResulter, options ...Option) error
 
-	var resulter NewListResulter[User]("count") // list only
-	err := qs.GetList(ctx, resulter)
+	/*Transaction run fn in transaction of querier db, savepoint when ctx already carries a tx, see gormqs.Transaction
 
-	var resulter NewListResulter[User]("list") // list only
-	err := qs.GetList(ctx, resulter)
+	err := qs.Transaction(ctx, func(ctx context.Context) error {
+		user, err := qs.GetOne(ctx, LockForUpdate(), Where("id = ?", 1))
+		...
+	})
 	*/
-	GetListTo(ctx context.Context, listResulter ListOrCountResulter, options ...Option) error
+	Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
 }
 
 type queries[M Model, Querier any] struct {
@@ -257,86 +382,357 @@ func (qs *queries[M, Q]) Querier() Q {
 	return qs.querier
 }
 
+func (qs *queries[M, Q]) DBInstance(ctx context.Context) *gorm.DB {
+	return qs.asQuerier().DBInstance(ctx)
+}
+
+func (qs *queries[M, Q]) Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	// tx is bound to the connection name of querier
+	if named, ok := any(qs.querier).(interface{ DBName() string }); ok {
+		opts = append([]TxOption{NamedTx(named.DBName())}, opts...)
+	}
+	return Transaction(ctx, qs.DBInstance(ctx), fn, opts...)
+}
+
 func (qs *queries[M, Q]) asQuerier() Querier {
 	return any(qs.querier).(Querier)
 }
 
 func (qs *queries[M, Q]) dbInstance(ctx context.Context, opts ...Option) *gorm.DB {
-	query := qs.asQuerier().DBInstance(ctx)
-	return Apply(query, opts)
+	return instance(ctx, qs.asQuerier(), opts)
+}
+
+func (qs *queries[M, Q]) readInstance(ctx context.Context, opts ...Option) *gorm.DB {
+	return readInstance(ctx, qs.asQuerier(), opts)
+}
+
+// guardFullTable refuse UPDATE and DELETE without WHERE clause unless AllowFullTable is used, regardless of gorm config
+func guardFullTable(db *gorm.DB) *gorm.DB {
+	allow, _ := db.Get(allowFullTableKey)
+
+	// session has its own config copy
+	tx := db.Session(&gorm.Session{})
+	tx.AllowGlobalUpdate = allow == true
+	return tx
+}
+
+// isScoped report whether statement has WHERE clause or model with primary key
+func isScoped(db *gorm.DB) bool {
+	if _, ok := db.Statement.Clauses["WHERE"]; ok {
+		return true
+	}
+
+	if db.Statement.Model == nil {
+		return false
+	}
+
+	sch, err := parseSchema(db, db.Statement.Model)
+	if err != nil {
+		return false
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(db.Statement.Model))
+	if rv.Kind() != reflect.Struct {
+		return false
+	}
+
+	for _, field := range sch.PrimaryFields {
+		if _, isZero := field.ValueOf(db.Statement.Context, rv); !isZero {
+			return true
+		}
+	}
+	return false
 }
 
 func (qs *queries[M, Q]) CreateOne(ctx context.Context, record *M) error {
-	return qs.dbInstance(ctx).Create(record).Error
+	return translateError(qs.dbInstance(ctx).Create(record).Error)
 }
 
 func (qs *queries[M, Q]) CreateMany(ctx context.Context, records *[]*M) error {
-	return qs.dbInstance(ctx).Create(records).Error
+	return translateError(qs.dbInstance(ctx).Create(records).Error)
+}
+
+func (qs *queries[M, Q]) UpsertOne(ctx context.Context, record *M, target ConflictTarget, action ConflictAction, opts ...Option) (int64, error) {
+	return qs.upsert(ctx, record, target, action, opts)
+}
+
+func (qs *queries[M, Q]) UpsertMany(ctx context.Context, records *[]*M, target ConflictTarget, action ConflictAction, opts ...Option) (int64, error) {
+	return qs.upsert(ctx, records, target, action, opts)
+}
+
+func (qs *queries[M, Q]) upsert(ctx context.Context, value any, target ConflictTarget, action ConflictAction, opts []Option) (int64, error) {
+	db := qs.dbInstance(ctx, opts...)
+	onConflict, err := buildOnConflict(db, &qs.model, target, action)
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := db.Clauses(onConflict).Create(value)
+	return cmd.RowsAffected, translateError(cmd.Error)
 }
 
 func (qs *queries[M, Q]) GetOne(ctx context.Context, opts ...Option) (*M, error) {
 	var result M
-	err := qs.dbInstance(ctx, opts...).Model(&result).First(&result).Error
+	err := withCount(qs.readInstance(ctx, opts...), func(tx *gorm.DB) error {
+		return tx.Model(&result).First(&result).Error
+	})
 	return &result, err
 }
 
 func (qs *queries[M, Q]) GetMany(ctx context.Context, opts ...Option) ([]*M, error) {
 	var result []*M
-	err := qs.dbInstance(ctx, opts...).Find(&result).Error
+	err := withCount(qs.readInstance(ctx, opts...), func(tx *gorm.DB) error {
+		return tx.Find(&result).Error
+	})
 	return result, err
 }
 
+// withCount run query, then Count intents on a copy of the session taken before the query
+func withCount(db *gorm.DB, query func(tx *gorm.DB) error) error {
+	value, _ := db.Get(countKey)
+	intents, _ := value.([]countIntent)
+	if len(intents) == 0 {
+		return translateError(query(db))
+	}
+
+	base := db.Session(&gorm.Session{Initialized: true})
+	if isConcurrent(db) {
+		return concurrentCount(db, base, intents, query)
+	}
+
+	if err := query(db); err != nil {
+		return translateError(err)
+	}
+
+	for _, intent := range intents {
+		if err := runCount(countSession(base, intent), intent.dest); err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+// countSession count query of intent on a copy of base
+func countSession(base *gorm.DB, intent countIntent) *gorm.DB {
+	tx := base.Session(&gorm.Session{Initialized: true})
+	tx.Statement.Settings.Delete(countKey)
+	return countOnly(Apply(tx, intent.opts))
+}
+
+// concurrentCount run query and Count intents concurrently, the first error cancel the other queries
+func concurrentCount(db, base *gorm.DB, intents []countIntent, query func(tx *gorm.DB) error) error {
+	ctx, cancel := context.WithCancelCause(db.Statement.Context)
+	defer cancel(nil)
+
+	var (
+		wg     sync.WaitGroup
+		totals = make([]int64, len(intents))
+		errs   = make([]error, len(intents)+1)
+	)
+
+	run := func(i int, fn func() error) {
+		wg.Go(func() {
+			if errs[i] = translateError(fn()); errs[i] != nil {
+				cancel(errs[i])
+			}
+		})
+	}
+
+	run(0, func() error {
+		return query(db.Session(&gorm.Session{Initialized: true, Context: ctx}))
+	})
+	for i, intent := range intents {
+		run(i+1, func() error {
+			return runCount(countSession(base.WithContext(ctx), intent), &totals[i])
+		})
+	}
+
+	wg.Wait()
+	if errors.Join(errs...) != nil {
+		// error of the query failed first, not the cancellation of the others
+		return context.Cause(ctx)
+	}
+
+	for i, intent := range intents {
+		*intent.dest = totals[i]
+	}
+	return nil
+}
+
+// countOnly remove limit, offset, order and preload which are meaningless for count
+func countOnly(db *gorm.DB) *gorm.DB {
+	db.Statement.Preloads = nil
+	delete(db.Statement.Clauses, "ORDER BY")
+	return WithoutLimitAndOffset()(db)
+}
+
+// isConcurrent report whether ConcurrentCount is used outside of transaction
+func isConcurrent(db *gorm.DB) bool {
+	if concurrent, _ := db.Get(concurrentCountKey); concurrent != true {
+		return false
+	}
+
+	return !inTransaction(db)
+}
+
+func (qs *queries[M, Q]) Iterate(ctx context.Context, opts ...Option) iter.Seq2[*M, error] {
+	return func(yield func(*M, error) bool) {
+		db := qs.readInstance(ctx, opts...)
+		rows, err := db.Rows()
+		if isDryRunErr(db, err) {
+			return
+		}
+		if err != nil {
+			yield(nil, translateError(err))
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			var record M
+			if err := db.ScanRows(rows, &record); err != nil {
+				yield(nil, translateError(err))
+				return
+			}
+
+			if !yield(&record, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(nil, translateError(err))
+		}
+	}
+}
+
+var (
+	ErrInvalidBatchSize = errors.New("gormqs: batch size must be positive")
+
+	// errStopIteration stop FindInBatches when consumer break
+	errStopIteration = errors.New("gormqs: stop iteration")
+)
+
+func (qs *queries[M, Q]) IterateBatches(ctx context.Context, batchSize int, opts ...Option) iter.Seq2[[]*M, error] {
+	return func(yield func([]*M, error) bool) {
+		if batchSize <= 0 {
+			yield(nil, fmt.Errorf("%w, got %d", ErrInvalidBatchSize, batchSize))
+			return
+		}
+
+		var batch []*M
+		err := qs.readInstance(ctx, opts...).FindInBatches(&batch, batchSize, func(_ *gorm.DB, _ int) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if !yield(batch, nil) {
+				return errStopIteration
+			}
+			return nil
+		}).Error
+
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(nil, translateError(err))
+		}
+	}
+}
+
 func (qs *queries[M, Q]) Update(ctx context.Context, record *M, opt Option, opts ...Option) (affectedRow int64, err error) {
 	defaultOpt := Options(WithModel(record), opt)
-	cmd := qs.dbInstance(ctx, defaultOpt, Options(opts...)).Updates(record)
-	affectedRow, err = cmd.RowsAffected, cmd.Error
+	db, restore, err := withVersion(guardFullTable(qs.dbInstance(ctx, defaultOpt, Options(opts...))), record)
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := db.Updates(record)
+	affectedRow, err = cmd.RowsAffected, translateError(cmd.Error)
+	if restore != nil {
+		dryRun := isDryRun(db)
+		if err == nil && affectedRow == 0 && !dryRun {
+			err = ErrStaleRecord
+		}
+		if err != nil || dryRun {
+			restore()
+		}
+	}
 	return
 }
 
 func (qs *queries[M, Q]) UpdateWithExpr(ctx context.Context, values map[string]clause.Expr, opt Option, opts ...Option) (affectedRow int64, err error) {
-	cmd := qs.dbInstance(ctx, opt, Options(opts...)).Updates(values)
-	affectedRow, err = cmd.RowsAffected, cmd.Error
+	// gorm only accept map[string]interface{}
+	updates := make(map[string]any, len(values))
+	for column, expr := range values {
+		updates[column] = expr
+	}
+
+	cmd := guardFullTable(qs.dbInstance(ctx, opt, Options(opts...))).Updates(updates)
+	affectedRow, err = cmd.RowsAffected, translateError(cmd.Error)
 	return
 }
 
 func (qs *queries[M, Q]) Count(ctx context.Context, opt Option, opts ...Option) (count int64, err error) {
-	err = qs.dbInstance(ctx, opt, Options(opts...)).Count(&count).Error
+	err = translateError(runCount(qs.readInstance(ctx, opt, Options(opts...)), &count))
 	return
 }
 
 func (qs *queries[M, Q]) Delete(ctx context.Context, opt Option, opts ...Option) (affectedRow int64, err error) {
 	model := qs.model
-	cmd := qs.dbInstance(ctx, opt, Options(opts...)).Delete(&model)
-	affectedRow, err = cmd.RowsAffected, cmd.Error
+	cmd := guardFullTable(qs.dbInstance(ctx, opt, Options(opts...))).Delete(&model)
+	affectedRow, err = cmd.RowsAffected, translateError(cmd.Error)
 	return
 }
 
 func (qs *queries[M, Q]) GetOneTo(ctx context.Context, r Model, opts ...Option) error {
-	return qs.dbInstance(ctx, opts...).First(r).Error
+	return translateError(qs.readInstance(ctx, opts...).First(r).Error)
 }
 
 func (qs *queries[M, Q]) GetManyTo(ctx context.Context, rList any, opts ...Option) error {
-	return qs.dbInstance(ctx, opts...).Find(rList).Error
+	return withCount(qs.readInstance(ctx, opts...), func(tx *gorm.DB) error {
+		return tx.Find(rList).Error
+	})
 }
 
 func (qs *queries[M, Q]) GetListTo(ctx context.Context, r ListOrCountResulter, opts ...Option) error {
-	switch {
-	case r.QsList() != nil && r.QsCount() != nil:
+	list, count := r.QsList(), r.QsCount()
+	if capper, ok := r.(ListCapper); ok && count != nil {
+		opts = append(slices.Clip(opts), reportCapped(capper.QsCapped()))
+	}
 
-		return qs.GetManyTo(ctx, r.QsList(), Options(opts...), Count(r.QsCount(), WithModel(qs.model)))
+	listOpts := opts
+	if optioner, ok := r.(ListOptioner); ok {
+		listOpts = append(slices.Clip(opts), optioner.QsListOption())
+	}
 
-	case r.QsList() != nil:
-		return qs.GetManyTo(ctx, r.QsList(), WithModel(qs.model), Options(opts...))
+	switch {
+	case list != nil && count != nil:
+		if err := qs.GetManyTo(ctx, list, Options(listOpts...), Count(count, WithModel(qs.model))); err != nil {
+			return err
+		}
 
-	case r.QsCount() != nil:
+	case list != nil:
+		if err := qs.GetManyTo(ctx, list, WithModel(qs.model), Options(listOpts...)); err != nil {
+			return err
+		}
+
+	case count != nil:
 		total, err := qs.Count(ctx, Options(opts...), WithoutLimitAndOffset())
 		if err != nil {
 			return err
 		}
-		*r.QsCount() = total
-		return nil
+		*count = total
 
+	default:
+		return errors.New("not support operation, one of resp or count must not nil")
 	}
 
-	return errors.New("not support operation, one of resp or count must not nil")
+	if finalizer, ok := r.(ListFinalizer); ok {
+		return finalizer.QsAfterList()
+	}
+	return nil
 }
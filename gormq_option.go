@@ -1,6 +1,8 @@
 package gormqs
 
 import (
+	"slices"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
@@ -42,6 +44,15 @@ func Omit(columns ...string) Option {
 	}
 }
 
+const allowFullTableKey = "gormqs:allow_full_table"
+
+// AllowFullTable allow Update, UpdateWithExpr and Delete to run without WHERE clause
+func AllowFullTable() Option {
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Set(allowFullTableKey, true)
+	}
+}
+
 func HardDelete() Option {
 	return func(q *gorm.DB) *gorm.DB {
 		return q.Unscoped()
@@ -54,20 +65,69 @@ func WithoutLimitAndOffset() Option {
 	}
 }
 
+/*
+OrderBy sorts in order, NULLS FIRST/LAST is emulated on dialects without the syntax
+
+	OrderBy(Desc("created_at"), Asc("id"))
+	// SQL: ORDER BY `users`.`created_at` DESC,`users`.`id`
+
+	OrderBy(Desc("paid_at").NullsLast())
+	// postgres: ORDER BY "orders"."paid_at" DESC NULLS LAST
+	// others:   ORDER BY CASE WHEN `orders`.`paid_at` IS NULL THEN 1 ELSE 0 END,`orders`.`paid_at` DESC
+*/
+func OrderBy(sorts ...Sort) Option {
+	return func(q *gorm.DB) *gorm.DB {
+		var columns []clause.OrderByColumn
+		for _, sort := range sorts {
+			columns = append(columns, sort.orderByColumns(q)...)
+		}
+
+		if len(columns) == 0 {
+			return q
+		}
+		return q.Order(clause.OrderBy{Columns: columns})
+	}
+}
+
 func LimitAndOffset(limit int, offset int) Option {
 	return func(q *gorm.DB) *gorm.DB {
 		return q.Limit(limit).Offset(offset)
 	}
 }
 
+const countKey = "gormqs:count"
+
+// countIntent recorded by Count, executed after the main query
+type countIntent struct {
+	dest *int64
+	opts []Option
+}
+
+/*
+Count total rows of the query into count, regardless of its position in options
+
+	users, err := qs.GetMany(ctx, LimitAndOffset(10, 0), Count(&total), Where("balance > ?", 0))
+	// SQL: SELECT * FROM `users` WHERE balance > 0 LIMIT 10
+	// SQL: SELECT count(*) FROM `users` WHERE balance > 0
+
+count run after the main query of GetOne, GetMany, GetManyTo and GetListTo in the same session or transaction,
+without limit, offset, order and preload. error of count query is returned by the method
+*/
 func Count(count *int64, countOpts ...Option) Option {
 	return func(q *gorm.DB) *gorm.DB {
-		newSession := q.Session(&gorm.Session{Initialized: true})
-		for _, opt := range countOpts {
-			opt(newSession)
-		}
-		WithoutLimitAndOffset()(newSession).Count(count)
-		return q
+		value, _ := q.Get(countKey)
+		intents, _ := value.([]countIntent)
+		return q.Set(countKey, append(slices.Clip(intents), countIntent{dest: count, opts: countOpts}))
+	}
+}
+
+const concurrentCountKey = "gormqs:concurrent_count"
+
+// ConcurrentCount run list and count queries of GetListTo, or query and Count of GetMany, concurrently on separate
+// connections, inside a transaction they still run one after another on the transaction connection
+func ConcurrentCount() Option {
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Set(concurrentCountKey, true)
 	}
 }
 
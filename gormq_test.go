@@ -0,0 +1,177 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils/tests"
+)
+
+type testUser struct {
+	ID       uint
+	Username string
+	Balance  float64
+}
+
+func (testUser) TableName() string {
+	return "users"
+}
+
+type testQueries[M gormqs.Model] struct {
+	gormqs.Queries[M, *testQueries[M]]
+	db    *gorm.DB
+	model M
+}
+
+func (qs *testQueries[M]) DBInstance(ctx context.Context) *gorm.DB {
+	db := gormqs.ContextValue(ctx, qs.db)
+	return db.WithContext(ctx).Table(qs.model.TableName()).Model(qs.model)
+}
+
+// newTestQueries return queries on a dry run db, every executed sql is append to sqls
+func newTestQueries[M gormqs.Model](t *testing.T) (*testQueries[M], *[]string) {
+	t.Helper()
+
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqls := new([]string)
+	capture := func(db *gorm.DB) {
+		*sqls = append(*sqls, db.Statement.SQL.String())
+	}
+
+	callbacks := db.Callback()
+	_ = callbacks.Create().After("gorm:create").Register("test:capture", capture)
+	_ = callbacks.Query().After("gorm:query").Register("test:capture", capture)
+	_ = callbacks.Update().After("gorm:update").Register("test:capture", capture)
+	_ = callbacks.Delete().After("gorm:delete").Register("test:capture", capture)
+
+	qs := &testQueries[M]{db: db}
+	qs.Queries = gormqs.NewQueries[M](qs)
+	return qs, sqls
+}
+
+// newSqliteQueries return queries on a sqlite database seeded with users, the pool has one connection
+// so rows left open block the next query
+func newSqliteQueries(t *testing.T, users ...*testUser) *testQueries[testUser] {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) > 0 {
+		if err := db.Create(users).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	qs := &testQueries[testUser]{db: db}
+	qs.Queries = gormqs.NewQueries[testUser](qs)
+	return qs
+}
+
+func lastSQL(t *testing.T, sqls *[]string) string {
+	t.Helper()
+	if len(*sqls) == 0 {
+		t.Fatal("no sql executed")
+	}
+	return (*sqls)[len(*sqls)-1]
+}
+
+func TestUpsertOne(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   gormqs.ConflictTarget
+		action   gormqs.ConflictAction
+		expected string
+	}{
+		{
+			name:     "do nothing",
+			action:   gormqs.DoNothing(),
+			expected: "INSERT INTO `users` (`username`,`balance`) VALUES (?,?) ON CONFLICT DO NOTHING RETURNING `id`",
+		},
+		{
+			name:     "update columns",
+			target:   gormqs.OnColumns("username"),
+			action:   gormqs.DoUpdateColumns("balance"),
+			expected: "INSERT INTO `users` (`username`,`balance`) VALUES (?,?) ON CONFLICT (`username`) DO UPDATE SET `balance`=`excluded`.`balance` RETURNING `id`",
+		},
+		{
+			name:     "update columns default to primary key target",
+			action:   gormqs.DoUpdateColumns("balance"),
+			expected: "INSERT INTO `users` (`username`,`balance`) VALUES (?,?) ON CONFLICT (`id`) DO UPDATE SET `balance`=`excluded`.`balance` RETURNING `id`",
+		},
+		{
+			name:     "nil action default to do nothing",
+			target:   gormqs.OnColumns("username"),
+			expected: "INSERT INTO `users` (`username`,`balance`) VALUES (?,?) ON CONFLICT (`username`) DO NOTHING RETURNING `id`",
+		},
+		{
+			name:     "constraint",
+			target:   gormqs.OnConstraint("users_username_key"),
+			action:   gormqs.DoNothing(),
+			expected: "INSERT INTO `users` (`username`,`balance`) VALUES (?,?) ON CONFLICT ON CONSTRAINT users_username_key DO NOTHING RETURNING `id`",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qs, sqls := newTestQueries[testUser](t)
+			user := testUser{Username: "foxie", Balance: 10}
+			if _, err := qs.UpsertOne(context.Background(), &user, test.target, test.action); err != nil {
+				t.Fatal(err)
+			}
+
+			if sql := lastSQL(t, sqls); sql != test.expected {
+				t.Errorf("got %s, want %s", sql, test.expected)
+			}
+		})
+	}
+}
+
+func TestDeleteWithoutWhere(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+	ctx := context.Background()
+
+	// global update allowed by gorm config must not bypass the guard
+	qs.db.AllowGlobalUpdate = true
+
+	_, err := qs.Delete(ctx, gormqs.If(false, gormqs.WhereID(1)))
+	if !errors.Is(err, gormqs.ErrMissingWhere) {
+		t.Fatalf("got %v, want ErrMissingWhere", err)
+	}
+
+	_, err = qs.UpdateWithExpr(ctx, map[string]clause.Expr{"balance": gorm.Expr("balance + ?", 1)}, gormqs.WithDebug())
+	if !errors.Is(err, gormqs.ErrMissingWhere) {
+		t.Fatalf("got %v, want ErrMissingWhere", err)
+	}
+
+	if _, err := qs.Delete(ctx, gormqs.AllowFullTable()); err != nil {
+		t.Fatal(err)
+	}
+
+	if sql := lastSQL(t, sqls); sql != "DELETE FROM `users`" {
+		t.Errorf("got %s, want DELETE FROM `users`", sql)
+	}
+}
@@ -0,0 +1,123 @@
+package gormqs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrUnsupportedConflictTarget = errors.New("gormqs: conflict target not supported by dialect")
+
+/*
+ConflictTarget decide which unique index trigger the conflict
+
+	OnColumns("username")         // ON CONFLICT ("username")
+	OnConstraint("users_pkey")    // ON CONFLICT ON CONSTRAINT users_pkey (postgres only)
+
+mysql always use ON DUPLICATE KEY UPDATE, target is ignored
+*/
+type ConflictTarget func(*clause.OnConflict)
+
+func OnColumns(columns ...string) ConflictTarget {
+	return func(c *clause.OnConflict) {
+		for _, column := range columns {
+			c.Columns = append(c.Columns, clause.Column{Name: column})
+		}
+	}
+}
+
+func OnConstraint(name string) ConflictTarget {
+	return func(c *clause.OnConflict) {
+		c.OnConstraint = name
+	}
+}
+
+/*
+ConflictAction decide what to do with the conflicted row
+
+	DoNothing()                                  // DO NOTHING
+	DoUpdateAll()                                // DO UPDATE SET every non primary column
+	DoUpdateColumns("balance")                   // DO UPDATE SET "balance" = excluded."balance"
+	DoUpdateExpr(map[string]clause.Expr{
+		"balance": gorm.Expr("balance + ?", 100),
+	})                                           // DO UPDATE SET "balance" = balance + 100
+*/
+type ConflictAction func(*clause.OnConflict)
+
+func DoNothing() ConflictAction {
+	return func(c *clause.OnConflict) {
+		c.DoNothing = true
+	}
+}
+
+func DoUpdateAll() ConflictAction {
+	return func(c *clause.OnConflict) {
+		c.UpdateAll = true
+	}
+}
+
+func DoUpdateColumns(columns ...string) ConflictAction {
+	return func(c *clause.OnConflict) {
+		c.DoUpdates = append(c.DoUpdates, clause.AssignmentColumns(columns)...)
+	}
+}
+
+func DoUpdateExpr(values map[string]clause.Expr) ConflictAction {
+	return func(c *clause.OnConflict) {
+		columns := make([]string, 0, len(values))
+		for column := range values {
+			columns = append(columns, column)
+		}
+		// keep generated sql stable
+		sort.Strings(columns)
+
+		for _, column := range columns {
+			c.DoUpdates = append(c.DoUpdates, clause.Assignment{
+				Column: clause.Column{Name: column},
+				Value:  values[column],
+			})
+		}
+	}
+}
+
+// buildOnConflict build clause.OnConflict for the dialect of db
+func buildOnConflict(db *gorm.DB, model any, target ConflictTarget, action ConflictAction) (clause.OnConflict, error) {
+	var onConflict clause.OnConflict
+	if target != nil {
+		target(&onConflict)
+	}
+	// conflict without action is ignored, empty DO UPDATE SET is invalid sql
+	if action == nil {
+		action = DoNothing()
+	}
+	action(&onConflict)
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		// ON DUPLICATE KEY UPDATE has no target
+		onConflict.Columns, onConflict.OnConstraint = nil, ""
+		return onConflict, nil
+
+	case "sqlite":
+		if onConflict.OnConstraint != "" {
+			return onConflict, fmt.Errorf("%w: sqlite has no ON CONSTRAINT, use OnColumns", ErrUnsupportedConflictTarget)
+		}
+	}
+
+	// DO UPDATE require target, use primary keys as default
+	if !onConflict.DoNothing && !onConflict.UpdateAll && onConflict.OnConstraint == "" && len(onConflict.Columns) == 0 {
+		sch, err := parseSchema(db, model)
+		if err != nil {
+			return onConflict, err
+		}
+
+		for _, field := range sch.PrimaryFields {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
+		}
+	}
+
+	return onConflict, nil
+}
@@ -0,0 +1,159 @@
+package gormqs
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Aggregate expression selected by GroupBy, scan into field or map value by alias
+type Aggregate struct {
+	Func   string
+	Column string
+	Alias  string
+}
+
+// CountAs COUNT(*) AS alias
+func CountAs(alias string) Aggregate {
+	return Aggregate{Func: "COUNT", Column: "*", Alias: alias}
+}
+
+// SumAs SUM(column) AS alias
+func SumAs(column, alias string) Aggregate {
+	return Aggregate{Func: "SUM", Column: column, Alias: alias}
+}
+
+// AvgAs AVG(column) AS alias
+func AvgAs(column, alias string) Aggregate {
+	return Aggregate{Func: "AVG", Column: column, Alias: alias}
+}
+
+// MinAs MIN(column) AS alias
+func MinAs(column, alias string) Aggregate {
+	return Aggregate{Func: "MIN", Column: column, Alias: alias}
+}
+
+// MaxAs MAX(column) AS alias
+func MaxAs(column, alias string) Aggregate {
+	return Aggregate{Func: "MAX", Column: column, Alias: alias}
+}
+
+func (a Aggregate) build() (string, []any) {
+	var (
+		sql  = a.Func + "(?)"
+		vars = []any{clause.Column{Name: a.Column}}
+	)
+
+	if a.Column == "*" {
+		sql, vars = a.Func+"(*)", nil
+	}
+
+	if a.Alias != "" {
+		sql += " AS ?"
+		vars = append(vars, clause.Column{Name: a.Alias})
+	}
+
+	return sql, vars
+}
+
+/*
+GroupBy select group columns and aggregates, then group by columns
+
+	GroupBy([]string{"user_id"}, CountAs("orders"), SumAs("pay_amount", "total"))
+	// SQL: SELECT `user_id`, COUNT(*) AS `orders`, SUM(`pay_amount`) AS `total` FROM `orders` GROUP BY `user_id`
+*/
+func GroupBy(columns []string, aggregates ...Aggregate) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		var (
+			sqls = make([]string, 0, len(columns)+len(aggregates))
+			vars = make([]any, 0, len(columns)+len(aggregates))
+		)
+
+		for _, column := range columns {
+			sqls = append(sqls, "?")
+			vars = append(vars, clause.Column{Name: column})
+		}
+
+		for _, aggregate := range aggregates {
+			sql, aggVars := aggregate.build()
+			sqls = append(sqls, sql)
+			vars = append(vars, aggVars...)
+		}
+
+		if len(sqls) > 0 {
+			db = db.Select(strings.Join(sqls, ", "), vars...)
+		}
+
+		for _, column := range columns {
+			db = db.Group(column)
+		}
+
+		return db
+	}
+}
+
+/*
+Having filter groups, postgres can not reference aggregate alias
+
+	Having("COUNT(*) > ?", 1)
+*/
+func Having(query any, args ...any) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Having(query, args...)
+	}
+}
+
+/*
+GroupTo scan grouped rows into caller row struct slice
+
+	type OrdersPerUser struct {
+		UserID uint
+		Orders int64
+		Total  float64
+	}
+
+	var rows []OrdersPerUser
+	err := gormqs.GroupTo(ctx, orderQueries, &rows,
+		GroupBy([]string{"user_id"}, CountAs("orders"), SumAs("pay_amount", "total")),
+		Having("COUNT(*) > ?", 1),
+	)
+*/
+func GroupTo(ctx context.Context, q Querier, rows any, opts ...Option) error {
+	return translateError(readInstance(ctx, q, opts).Find(rows).Error)
+}
+
+/*
+GroupMap group by key column and map to aggregate value, null key is mapped to zero value of K
+
+	ordersPerUser, err := gormqs.GroupMap[uint, int64](ctx, orderQueries, "user_id", CountAs("orders"))
+	// SQL: SELECT `user_id`, COUNT(*) AS `orders` FROM `orders` GROUP BY `user_id`
+*/
+func GroupMap[K comparable, V any](ctx context.Context, q Querier, key string, value Aggregate, opts ...Option) (map[K]V, error) {
+	tx := readInstance(ctx, q, opts)
+	tx = GroupBy([]string{key}, value)(tx)
+	rows, err := tx.Rows()
+	if isDryRunErr(tx, err) {
+		return map[K]V{}, nil
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	result := map[K]V{}
+	for rows.Next() {
+		var (
+			k sql.Null[K]
+			v sql.Null[V]
+		)
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, translateError(err)
+		}
+		result[k.V] = v.V
+	}
+
+	return result, translateError(rows.Err())
+}
@@ -0,0 +1,31 @@
+package gormqs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+)
+
+func TestGroupTo(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+
+	type balanceGroup struct {
+		Balance float64
+		Users   int64
+	}
+
+	var rows []balanceGroup
+	err := gormqs.GroupTo(context.Background(), qs, &rows,
+		gormqs.GroupBy([]string{"balance"}, gormqs.CountAs("users"), gormqs.MaxAs("id", "max_id")),
+		gormqs.Having("COUNT(*) > ?", 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "SELECT `balance`, COUNT(*) AS `users`, MAX(`id`) AS `max_id` FROM `users` GROUP BY `balance` HAVING COUNT(*) > ?"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
@@ -0,0 +1,122 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/gormqs"
+)
+
+func seedUsers(n int) []*testUser {
+	users := make([]*testUser, n)
+	for i := range users {
+		users[i] = &testUser{Username: string(rune('a' + i)), Balance: float64(i + 1)}
+	}
+	return users
+}
+
+func TestIterate(t *testing.T) {
+	qs := newSqliteQueries(t, seedUsers(5)...)
+	ctx := context.Background()
+
+	var names []string
+	for user, err := range qs.Iterate(ctx, gormqs.Where("balance > ?", 1), gormqs.OrderBy(gormqs.Asc("id"))) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, user.Username)
+	}
+	if !slices.Equal(names, []string{"b", "c", "d", "e"}) {
+		t.Errorf("got %v, want [b c d e]", names)
+	}
+
+	t.Run("break", func(t *testing.T) {
+		for _, err := range qs.Iterate(ctx) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+
+		// the only connection is released when rows are closed
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		if count, err := qs.Count(ctx, gormqs.Where("1 = 1")); err != nil || count != 5 {
+			t.Errorf("got %d, %v after break, want 5", count, err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var rows, errs int
+		for _, err := range qs.Iterate(ctx) {
+			if err != nil {
+				errs++
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("got %v, want context.Canceled", err)
+				}
+				continue
+			}
+
+			rows++
+			cancel()
+		}
+		if rows != 1 || errs != 1 {
+			t.Errorf("got %d rows and %d errors, want 1 row then the ctx error", rows, errs)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		failures := map[string]gormqs.Option{
+			"query": gormqs.Where("unknown_column = ?", 1),
+			"scan":  gormqs.Select("id", "'not a number' AS balance"),
+		}
+
+		for name, opt := range failures {
+			var yields int
+			for user, err := range qs.Iterate(ctx, opt) {
+				yields++
+				if err == nil || user != nil {
+					t.Errorf("%s: got %v, %v, want error only", name, user, err)
+				}
+			}
+			if yields != 1 {
+				t.Errorf("%s: got %d yields, want the error once", name, yields)
+			}
+		}
+	})
+}
+
+func TestIterateBatches(t *testing.T) {
+	qs := newSqliteQueries(t, seedUsers(5)...)
+	ctx := context.Background()
+
+	var sizes []int
+	for batch, err := range qs.IterateBatches(ctx, 2) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(batch))
+	}
+	if !slices.Equal(sizes, []int{2, 2, 1}) {
+		t.Errorf("got batch sizes %v, want [2 2 1]", sizes)
+	}
+
+	for _, size := range []int{0, -1} {
+		var yields int
+		for batch, err := range qs.IterateBatches(ctx, size) {
+			yields++
+			if !errors.Is(err, gormqs.ErrInvalidBatchSize) || batch != nil {
+				t.Errorf("batch size %d: got %v, %v, want ErrInvalidBatchSize", size, batch, err)
+			}
+		}
+		if yields != 1 {
+			t.Errorf("batch size %d: got %d yields, want the error once", size, yields)
+		}
+	}
+}
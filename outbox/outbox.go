@@ -0,0 +1,219 @@
+/*
+Package outbox transactional outbox on top of gormqs, events are written in the same transaction
+as business writes and published by a Relay after commit, at least once
+
+	outboxQueries := outbox.NewQueries(db)
+
+	err := gormqs.Transaction(ctx, db, func(ctx context.Context) error {
+		if err := orderQueries.CreateOne(ctx, order); err != nil {
+			return err
+		}
+		// same tx as order, rolled back together
+		_, err := outboxQueries.Enqueue(ctx, "order.created", strconv.Itoa(int(order.ID)), order)
+		return err
+	})
+
+	relay := outbox.NewRelay(outboxQueries, outbox.PublisherFunc(func(ctx context.Context, msg *outbox.Message) error {
+		return broker.Publish(ctx, msg.Topic, msg.Key, msg.Payload)
+	}))
+	go relay.Run(ctx)
+
+messages are claimed with SELECT ... FOR UPDATE SKIP LOCKED on postgres and mysql, other dialects
+like sqlite rely on the claim UPDATE only, so many relays can run at the same time
+*/
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ gormqs.Model = (*Message)(nil)
+
+// ErrLeaseLost lease of message is over and it was claimed again, the other claim owns it now
+var ErrLeaseLost = errors.New("outbox: lease lost")
+
+// Status of message
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusDone       Status = "done"
+	StatusDead       Status = "dead"
+)
+
+// Message outbox row, migrate it with db.AutoMigrate(&outbox.Message{})
+type Message struct {
+	ID          uint64     `gorm:"primaryKey" json:"id"`
+	Topic       string     `gorm:"size:255;not null" json:"topic"`
+	Key         string     `gorm:"size:255" json:"key"`
+	Payload     []byte     `gorm:"not null" json:"payload"`
+	Status      Status     `gorm:"size:16;not null;default:pending;index:idx_outbox_messages_claim,priority:1" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	AvailableAt time.Time  `gorm:"not null;index:idx_outbox_messages_claim,priority:2" json:"availableAt"`
+	ClaimToken  string     `gorm:"size:32;index" json:"-"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+// Queries of outbox messages, use the tx of ctx like other gormqs queries
+type Queries struct {
+	gormqs.DefaultQueries[Message]
+}
+
+// NewQueries outbox queries, gormqs.WithTableName to use another table
+func NewQueries(db *gorm.DB, cfgOpts ...gormqs.ConfigOption) *Queries {
+	return &Queries{gormqs.New[Message](db, cfgOpts...)}
+}
+
+/*
+Enqueue write a pending message, payload []byte is stored as is, other values are encoded to JSON.
+ctx should carry the tx of the business writes, otherwise the message is committed on its own
+*/
+func (qs *Queries) Enqueue(ctx context.Context, topic, key string, payload any) (*Message, error) {
+	data, ok := payload.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := &Message{
+		Topic:       topic,
+		Key:         key,
+		Payload:     data,
+		Status:      StatusPending,
+		AvailableAt: utcNow(),
+	}
+	if err := qs.CreateOne(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+/*
+Claim lease up to limit available messages ordered by id, pending ones and processing ones with expired lease.
+claimed messages are not claimed again until lease is over, MarkDone or MarkFailed release them
+*/
+func (qs *Queries) Claim(ctx context.Context, limit int, lease time.Duration) ([]*Message, error) {
+	var (
+		now   = utcNow()
+		until = now.Add(lease)
+		token = newClaimToken()
+	)
+
+	err := qs.Transaction(ctx, func(ctx context.Context) error {
+		ids, err := gormqs.Pluck[uint64](ctx, qs, "id",
+			claimable(now),
+			gormqs.OrderBy(gormqs.Asc("id")),
+			gormqs.LimitAndOffset(limit, 0),
+			skipLocked(),
+		)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		// conditional update, rows claimed by another relay in the meantime are skipped
+		_, err = qs.Update(ctx,
+			&Message{Status: StatusProcessing, ClaimToken: token, LockedUntil: &until},
+			gormqs.Select("status", "claim_token", "locked_until"),
+			gormqs.Where("id IN ?", ids),
+			claimable(now),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return qs.GetMany(ctx, gormqs.Where("claim_token = ?", token), gormqs.OrderBy(gormqs.Asc("id")))
+}
+
+// MarkDone message published, ErrLeaseLost when the lease was lost to another relay
+func (qs *Queries) MarkDone(ctx context.Context, msg *Message) error {
+	now := utcNow()
+	update := *msg
+	update.Status, update.ProcessedAt, update.LockedUntil = StatusDone, &now, nil
+
+	return qs.release(ctx, msg, &update, "status", "processed_at", "locked_until", "claim_token")
+}
+
+// MarkFailed count failed attempt, message is available again at retryAt or dead when dead is true.
+// ErrLeaseLost when the lease was lost to another relay
+func (qs *Queries) MarkFailed(ctx context.Context, msg *Message, cause error, retryAt time.Time, dead bool) error {
+	update := *msg
+	update.Attempts++
+	update.LastError, update.LockedUntil = cause.Error(), nil
+	if dead {
+		update.Status = StatusDead
+	} else {
+		update.Status, update.AvailableAt = StatusPending, retryAt
+	}
+
+	return qs.release(ctx, msg, &update, "status", "attempts", "last_error", "available_at", "locked_until", "claim_token")
+}
+
+// release update columns of message still claimed by its token with update, msg is replaced by update on success
+func (qs *Queries) release(ctx context.Context, msg, update *Message, columns ...string) error {
+	update.ClaimToken = ""
+
+	affectedRow, err := qs.Update(ctx, update, gormqs.Select(columns), gormqs.Where("claim_token = ?", msg.ClaimToken))
+	if err != nil {
+		return err
+	}
+	if affectedRow == 0 && !isDryRun(ctx) {
+		return ErrLeaseLost
+	}
+
+	*msg = *update
+	return nil
+}
+
+// isDryRun report whether ctx is from gormqs.DryRun, no row is affected then
+func isDryRun(ctx context.Context) bool {
+	return gormqs.ContextValue[*gormqs.SQLCollector](ctx, nil) != nil
+}
+
+// claimable pending messages available at now and processing messages with expired lease
+func claimable(now time.Time) gormqs.Option {
+	return gormqs.Where("(status = ? AND available_at <= ?) OR (status = ? AND locked_until <= ?)",
+		StatusPending, now, StatusProcessing, now)
+}
+
+// skipLocked FOR UPDATE SKIP LOCKED on dialects supporting it
+func skipLocked() gormqs.Option {
+	return func(db *gorm.DB) *gorm.DB {
+		switch db.Dialector.Name() {
+		case "postgres", "mysql":
+			return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked})
+		}
+		return db
+	}
+}
+
+func newClaimToken() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// now in UTC, times are compared as text on sqlite
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
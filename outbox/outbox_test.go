@@ -0,0 +1,133 @@
+package outbox_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"github.com/foxie-io/gormqs/outbox"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// fakePool begin transactions without database, queries only run in dry run
+type fakePool struct {
+	gorm.ConnPool
+}
+
+func (fakePool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &fakeTx{}, nil
+}
+
+type fakeTx struct {
+	gorm.ConnPool
+}
+
+func (*fakeTx) Commit() error   { return nil }
+func (*fakeTx) Rollback() error { return nil }
+
+type postgresDialector struct {
+	tests.DummyDialector
+}
+
+func (postgresDialector) Name() string {
+	return "postgres"
+}
+
+func TestClaimSkipLocked(t *testing.T) {
+	dialects := []struct {
+		name       string
+		dialector  gorm.Dialector
+		skipLocked bool
+	}{
+		{"postgres", postgresDialector{}, true},
+		{"other", tests.DummyDialector{}, false},
+	}
+
+	for _, tt := range dialects {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(tt.dialector, &gorm.Config{ConnPool: fakePool{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var collector gormqs.SQLCollector
+			ctx := gormqs.DryRun(context.Background(), &collector)
+			if _, err := outbox.NewQueries(db).Claim(ctx, 10, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			claim := collector.Statements()[0].SQL
+			if !strings.HasPrefix(claim, "SELECT `id` FROM `outbox_messages` WHERE (status = ? AND available_at <= ?) OR (status = ? AND locked_until <= ?) ORDER BY") {
+				t.Errorf("unexpected claim query %s", claim)
+			}
+			if got := strings.HasSuffix(claim, "FOR UPDATE SKIP LOCKED"); got != tt.skipLocked {
+				t.Errorf("expected skip locked %t, got %s", tt.skipLocked, claim)
+			}
+		})
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var collector gormqs.SQLCollector
+	ctx := gormqs.DryRun(context.Background(), &collector)
+	msg, err := outbox.NewQueries(db).Enqueue(ctx, "order.created", "1", map[string]int{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(msg.Payload) != `{"id":1}` || msg.Status != outbox.StatusPending {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if stmt := collector.Statements()[0].SQL; !strings.HasPrefix(stmt, "INSERT INTO `outbox_messages`") {
+		t.Errorf("unexpected insert %s", stmt)
+	}
+}
+
+// rowsPool execute statements affecting rows rows
+type rowsPool struct {
+	gorm.ConnPool
+	rows int64
+}
+
+func (p rowsPool) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return driver.RowsAffected(p.rows), nil
+}
+
+func TestMarkDoneLeaseLost(t *testing.T) {
+	for _, rows := range []int64{0, 1} {
+		db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{ConnPool: rowsPool{rows: rows}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg := &outbox.Message{ID: 1, Status: outbox.StatusProcessing, ClaimToken: "token"}
+		err = outbox.NewQueries(db).MarkDone(context.Background(), msg)
+
+		if rows == 0 {
+			if !errors.Is(err, outbox.ErrLeaseLost) {
+				t.Errorf("got %v, want ErrLeaseLost", err)
+			}
+			if msg.Status != outbox.StatusProcessing || msg.ClaimToken != "token" {
+				t.Errorf("message of lost lease must not change, got %+v", msg)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Status != outbox.StatusDone || msg.ClaimToken != "" || msg.ProcessedAt == nil {
+			t.Errorf("unexpected released message %+v", msg)
+		}
+	}
+}
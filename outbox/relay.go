@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Publisher publish message to broker, message is retried on error
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// PublisherFunc function as Publisher
+type PublisherFunc func(ctx context.Context, msg *Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+type relayConfig struct {
+	batchSize    int
+	pollInterval time.Duration
+	lease        time.Duration
+	maxAttempts  int
+	backoff      func(attempt int) time.Duration
+	onError      func(err error)
+	onDead       func(ctx context.Context, msg *Message)
+}
+
+type RelayOption func(*relayConfig)
+
+// WithBatchSize messages claimed per batch, default 100
+func WithBatchSize(n int) RelayOption {
+	return func(c *relayConfig) {
+		c.batchSize = n
+	}
+}
+
+// WithPollInterval wait between batches when there is no more message, default 1s
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(c *relayConfig) {
+		c.pollInterval = d
+	}
+}
+
+// WithLease time a claimed batch is reserved to the relay, must be longer than publishing it, default 30s
+func WithLease(d time.Duration) RelayOption {
+	return func(c *relayConfig) {
+		c.lease = d
+	}
+}
+
+// WithMaxAttempts failed attempts before a message is dead, default 10
+func WithMaxAttempts(n int) RelayOption {
+	return func(c *relayConfig) {
+		c.maxAttempts = n
+	}
+}
+
+// WithRetryBackoff delay before next attempt of a failed message, default 1s doubled up to 1h
+func WithRetryBackoff(backoff func(attempt int) time.Duration) RelayOption {
+	return func(c *relayConfig) {
+		c.backoff = backoff
+	}
+}
+
+// OnError called with errors of claiming and marking in Run, Run keeps polling
+func OnError(fn func(err error)) RelayOption {
+	return func(c *relayConfig) {
+		c.onError = fn
+	}
+}
+
+// OnDead called when a message reach max attempts and is moved to dead status
+func OnDead(fn func(ctx context.Context, msg *Message)) RelayOption {
+	return func(c *relayConfig) {
+		c.onDead = fn
+	}
+}
+
+// Relay claim pending messages and publish them
+type Relay struct {
+	queries   *Queries
+	publisher Publisher
+	config    relayConfig
+}
+
+func NewRelay(queries *Queries, publisher Publisher, opts ...RelayOption) *Relay {
+	cfg := relayConfig{
+		batchSize:    100,
+		pollInterval: time.Second,
+		lease:        30 * time.Second,
+		maxAttempts:  10,
+		backoff:      defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Relay{queries: queries, publisher: publisher, config: cfg}
+}
+
+/*
+ProcessBatch claim one batch and publish its messages in order, return number of claimed messages.
+failed messages are retried after backoff, dead after max attempts
+*/
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	msgs, err := r.queries.Claim(ctx, r.config.batchSize, r.config.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			// unpublished messages are claimed again when lease is over
+			return len(msgs), errors.Join(append(errs, err)...)
+		}
+
+		if err := r.dispatch(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(msgs), errors.Join(errs...)
+}
+
+// Run process batches until ctx is done, return cause of ctx
+func (r *Relay) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-timer.C:
+		}
+
+		n, err := r.ProcessBatch(ctx)
+		if err != nil && r.config.onError != nil && ctx.Err() == nil {
+			r.config.onError(err)
+		}
+
+		// full batch, more messages may be waiting
+		wait := r.config.pollInterval
+		if err == nil && n == r.config.batchSize {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// dispatch publish message and mark it, error is the marking error, ErrLeaseLost when another relay owns it
+func (r *Relay) dispatch(ctx context.Context, msg *Message) error {
+	publishErr := r.publisher.Publish(ctx, msg)
+	if publishErr == nil {
+		return r.queries.MarkDone(ctx, msg)
+	}
+
+	dead := msg.Attempts+1 >= r.config.maxAttempts
+	retryAt := utcNow().Add(r.config.backoff(msg.Attempts + 1))
+	if err := r.queries.MarkFailed(ctx, msg, publishErr, retryAt, dead); err != nil {
+		return err
+	}
+
+	if dead && r.config.onDead != nil {
+		r.config.onDead(ctx, msg)
+	}
+	return nil
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return min(delay, time.Hour)
+}
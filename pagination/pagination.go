@@ -0,0 +1,200 @@
+/*
+Package pagination page/size pagination implementing gormqs.ListOrCountResulter
+
+	// GET /users?page=2&size=20&select=list,count, select is parsed by gormqs.ParseSelection
+	page, err := pagination.Parse[*models.User](r.URL.Query(), pagination.WithMaxSize(50))
+	if err != nil {
+		// 400 Bad Request
+	}
+
+	if err := userQueries.GetListTo(ctx, page); err != nil {
+		return err
+	}
+
+	json.NewEncoder(w).Encode(page)
+	// {"data":[...],"total":100,"page":2,"size":20,"totalPages":5,"hasNext":true}
+
+has next is detected by fetching one more row than the page size, so it is exact even with
+gormqs.CappedCount or gormqs.EstimatedCount where total and total pages are approximate.
+capped is true when the total is capped by gormqs.CappedCount, more rows exist than total
+
+	page, err := pagination.Parse[*models.User](r.URL.Query())
+	err = userQueries.GetListTo(ctx, page, gormqs.WithCountStrategy(gormqs.CappedCount(10000)))
+*/
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/foxie-io/gormqs"
+)
+
+var ErrInvalidParam = errors.New("pagination: invalid param")
+
+var (
+	_ gormqs.ListOrCountResulter = (*Page[any])(nil)
+	_ gormqs.ListOptioner        = (*Page[any])(nil)
+	_ gormqs.ListFinalizer       = (*Page[any])(nil)
+	_ gormqs.ListCapper          = (*Page[any])(nil)
+)
+
+type config struct {
+	defaultSize int
+	maxSize     int
+	pageKey     string
+	sizeKey     string
+	selectKey   string
+}
+
+type Option func(*config)
+
+// WithDefaultSize size when size param is missing, default 10
+func WithDefaultSize(size int) Option {
+	return func(c *config) {
+		c.defaultSize = size
+	}
+}
+
+// WithMaxSize clamp size param, default 100
+func WithMaxSize(size int) Option {
+	return func(c *config) {
+		c.maxSize = size
+	}
+}
+
+// WithKeys query keys of page, size and select, default "page", "size" and "select"
+func WithKeys(page, size, selects string) Option {
+	return func(c *config) {
+		c.pageKey, c.sizeKey, c.selectKey = page, size, selects
+	}
+}
+
+// Page request and result of a page, T is element of list e.g. *models.User
+type Page[T any] struct {
+	Data       *[]T   `json:"data,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+	TotalPages *int64 `json:"totalPages,omitempty"`
+	Capped     bool   `json:"capped,omitempty"`
+	HasNext    *bool  `json:"hasNext,omitempty"`
+
+	list  bool
+	count bool
+}
+
+// New page of selection, list and count are selected when selection is empty, has next is always reported
+func New[T any](page, size int, selection gormqs.Selection) (*Page[T], error) {
+	if page < 1 || size < 1 {
+		return nil, fmt.Errorf("%w: page and size must be positive", ErrInvalidParam)
+	}
+
+	if selection == 0 {
+		selection = gormqs.SelectList | gormqs.SelectCount
+	}
+
+	return &Page[T]{
+		Page:  page,
+		Size:  size,
+		list:  selection.Has(gormqs.SelectList) || selection.Has(gormqs.SelectHasNext),
+		count: selection.Has(gormqs.SelectCount),
+	}, nil
+}
+
+// Parse page from query values, page below 1 is 1 and size is clamped to max size
+func Parse[T any](values url.Values, opts ...Option) (*Page[T], error) {
+	cfg := config{defaultSize: 10, maxSize: 100, pageKey: "page", sizeKey: "size", selectKey: "select"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	page, err := parseInt(values, cfg.pageKey, 1)
+	if err != nil {
+		return nil, err
+	}
+	size, err := parseInt(values, cfg.sizeKey, cfg.defaultSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page = max(page, 1)
+	size = max(min(size, cfg.maxSize), 1)
+
+	selection, err := gormqs.ParseSelection(values.Get(cfg.selectKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidParam, err)
+	}
+
+	return New[T](page, size, selection)
+}
+
+func parseInt(values url.Values, key string, fallback int) (int, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be a number", ErrInvalidParam, key)
+	}
+	return value, nil
+}
+
+// Offset of first row
+func (p *Page[T]) Offset() int {
+	return (p.Page - 1) * p.Size
+}
+
+func (p *Page[T]) QsList() any {
+	if !p.list {
+		return nil
+	}
+	if p.Data == nil {
+		p.Data = new([]T)
+	}
+	return p.Data
+}
+
+func (p *Page[T]) QsCount() *int64 {
+	if !p.count {
+		return nil
+	}
+	if p.Total == nil {
+		p.Total = new(int64)
+	}
+	return p.Total
+}
+
+func (p *Page[T]) QsCapped() *bool {
+	p.Capped = false
+	return &p.Capped
+}
+
+// QsListOption fetch one more row to detect next page, count may be capped or estimated
+func (p *Page[T]) QsListOption() gormqs.Option {
+	return gormqs.LimitAndOffset(p.Size+1, p.Offset())
+}
+
+func (p *Page[T]) QsAfterList() error {
+	var hasNext bool
+
+	if p.Total != nil {
+		totalPages := (*p.Total + int64(p.Size) - 1) / int64(p.Size)
+		p.TotalPages = &totalPages
+		hasNext = int64(p.Page) < totalPages
+	}
+
+	if p.Data != nil {
+		hasNext = len(*p.Data) > p.Size
+		if hasNext {
+			*p.Data = (*p.Data)[:p.Size]
+		}
+	}
+
+	p.HasNext = &hasNext
+	return nil
+}
@@ -0,0 +1,57 @@
+package pagination_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"github.com/foxie-io/gormqs/pagination"
+)
+
+func TestParse(t *testing.T) {
+	page, err := pagination.Parse[int](url.Values{"page": {"0"}, "size": {"500"}, "select": {"list"}}, pagination.WithMaxSize(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Page != 1 || page.Size != 50 {
+		t.Errorf("got page %d size %d, want page 1 size 50", page.Page, page.Size)
+	}
+	if page.QsList() == nil || page.QsCount() != nil {
+		t.Error("select list must only query list")
+	}
+
+	for _, values := range []url.Values{{"page": {"abc"}}, {"select": {"list,rows"}}} {
+		if _, err := pagination.Parse[int](values); !errors.Is(err, pagination.ErrInvalidParam) {
+			t.Errorf("%v: got %v, want ErrInvalidParam", values, err)
+		}
+	}
+}
+
+func TestPageHasNext(t *testing.T) {
+	t.Run("without count", func(t *testing.T) {
+		page, _ := pagination.New[int](1, 2, gormqs.SelectList)
+		*page.QsList().(*[]int) = []int{1, 2, 3}
+
+		if err := page.QsAfterList(); err != nil {
+			t.Fatal(err)
+		}
+		if len(*page.Data) != 2 || !*page.HasNext {
+			t.Errorf("got %v has next %v, want 2 rows and next page", *page.Data, *page.HasNext)
+		}
+	})
+
+	t.Run("with count", func(t *testing.T) {
+		page, _ := pagination.New[int](3, 2, 0)
+		*page.QsList().(*[]int) = []int{5, 6}
+		*page.QsCount() = 6
+
+		if err := page.QsAfterList(); err != nil {
+			t.Fatal(err)
+		}
+		if *page.TotalPages != 3 || *page.HasNext {
+			t.Errorf("got total pages %d has next %v, want 3 and last page", *page.TotalPages, *page.HasNext)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package gormqs
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type (
+	readKey    struct{}
+	primaryKey struct{}
+)
+
+/*
+Resolver route reads to replicas and writes to primary, see WithResolver
+
+	resolver := gormqs.NewResolver(primaryDB, replicaDB1, replicaDB2)
+	userQueries := gormqs.New[models.User](primaryDB, gormqs.WithResolver(resolver))
+
+	userQueries.GetMany(ctx)           // replica
+	userQueries.CreateOne(ctx, user)   // primary
+	userQueries.GetOne(gormqs.UsePrimary(ctx), gormqs.WhereID(user.ID)) // primary, read your writes
+
+transaction of ctx is always used, reads inside gormqs.Transaction run on primary
+*/
+type Resolver struct {
+	primary  *gorm.DB
+	replicas []*gorm.DB
+	next     atomic.Uint64
+}
+
+// NewResolver primary with replicas picked in round robin, reads use primary without replica
+func NewResolver(primary *gorm.DB, replicas ...*gorm.DB) *Resolver {
+	return &Resolver{primary: primary, replicas: replicas}
+}
+
+// Primary db for writes
+func (r *Resolver) Primary() *gorm.DB {
+	return r.primary
+}
+
+// Resolve db of ctx, tx of ctx first, replica for reads unless UsePrimary, primary otherwise.
+// queries of WithDBName look up tx of their name instead
+func (r *Resolver) Resolve(ctx context.Context) *gorm.DB {
+	if db := ContextDB(ctx, "", nil); db != nil {
+		return db
+	}
+	return r.pick(ctx)
+}
+
+// pick replica for reads unless UsePrimary, primary otherwise
+func (r *Resolver) pick(ctx context.Context) *gorm.DB {
+	if len(r.replicas) == 0 || !IsRead(ctx) || isPrimary(ctx) {
+		return r.primary
+	}
+
+	i := r.next.Add(1) - 1
+	return r.replicas[i%uint64(len(r.replicas))]
+}
+
+// UsePrimary force reads of ctx to primary, e.g. read after write when replicas may lag
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// IsRead report whether ctx passed to Querier.DBInstance is for a read query, e.g. GetMany or Count
+func IsRead(ctx context.Context) bool {
+	read, _ := ctx.Value(readKey{}).(bool)
+	return read
+}
+
+func isPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// readInstance instance for read query, read intent is only visible to DBInstance
+func readInstance(ctx context.Context, q Querier, opts []Option) *gorm.DB {
+	db := instance(context.WithValue(ctx, readKey{}, true), q, opts)
+
+	// hooks and callbacks of the query may write with the statement context,
+	// new session so a shared db returned by DBInstance is never changed
+	return db.WithContext(ctx)
+}
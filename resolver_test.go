@@ -0,0 +1,112 @@
+package gormqs_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// routesMu guard routes of queries running concurrently
+var routesMu sync.Mutex
+
+// routedDB dry run db recording its name for every executed query
+func routedDB(t *testing.T, name string, routes *[]string) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(*gorm.DB) {
+		routesMu.Lock()
+		defer routesMu.Unlock()
+		*routes = append(*routes, name)
+	}
+	_ = db.Callback().Create().After("gorm:create").Register("test:route", record)
+	_ = db.Callback().Query().After("gorm:query").Register("test:route", record)
+	return db
+}
+
+func TestResolver(t *testing.T) {
+	var (
+		routes   = new([]string)
+		primary  = routedDB(t, "primary", routes)
+		resolver = gormqs.NewResolver(primary, routedDB(t, "replica1", routes), routedDB(t, "replica2", routes))
+		qs       = gormqs.New[testUser](primary, gormqs.WithResolver(resolver))
+		ctx      = context.Background()
+	)
+
+	_, _ = qs.GetMany(ctx)
+	_, _ = qs.Count(ctx, gormqs.Where("balance > ?", 0))
+	_ = qs.CreateOne(ctx, &testUser{Username: "a"})
+	_, _ = qs.GetOne(gormqs.UsePrimary(ctx))
+	_, _ = gormqs.Pluck[string](ctx, qs, "username")
+
+	// bound to db of ctx like a transaction
+	_, _ = qs.GetMany(gormqs.ContextWithValue(ctx, primary))
+
+	expected := []string{"replica1", "replica2", "primary", "primary", "replica1", "primary"}
+	if !reflect.DeepEqual(*routes, expected) {
+		t.Errorf("expected %v, got %v", expected, *routes)
+	}
+}
+
+func TestResolverConcurrentCount(t *testing.T) {
+	var (
+		routes   = new([]string)
+		primary  = routedDB(t, "primary", routes)
+		resolver = gormqs.NewResolver(primary, routedDB(t, "replica1", routes), routedDB(t, "replica2", routes))
+		qs       = gormqs.New[testUser](primary, gormqs.WithResolver(resolver))
+		ctx      = context.Background()
+	)
+
+	// list and count share one replica pick
+	resulter := gormqs.NewListResulter[testUser](gormqs.SelectList | gormqs.SelectCount)
+	if err := qs.GetListTo(ctx, resulter, gormqs.ConcurrentCount()); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = qs.GetMany(ctx)
+
+	expected := []string{"replica1", "replica1", "replica2"}
+	if !reflect.DeepEqual(*routes, expected) {
+		t.Errorf("expected %v, got %v", expected, *routes)
+	}
+}
+
+// resolvedQueries querier returning db of resolver as is, like a custom DBInstance
+type resolvedQueries struct {
+	gormqs.Queries[testUser, *resolvedQueries]
+	resolver *gormqs.Resolver
+}
+
+func (qs *resolvedQueries) DBInstance(ctx context.Context) *gorm.DB {
+	return qs.resolver.Resolve(ctx)
+}
+
+type requestKey struct{}
+
+func TestResolverKeepRootContext(t *testing.T) {
+	var (
+		routes  = new([]string)
+		primary = routedDB(t, "primary", routes)
+		replica = routedDB(t, "replica", routes)
+		qs      = &resolvedQueries{resolver: gormqs.NewResolver(primary, replica)}
+	)
+	qs.Queries = gormqs.NewQueries[testUser](qs)
+
+	rootCtx := replica.Statement.Context
+	ctx := context.WithValue(context.Background(), requestKey{}, "request-1")
+	if _, err := qs.GetMany(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if replica.Statement.Context != rootCtx {
+		t.Errorf("read leaked request ctx into the root db, got %v", replica.Statement.Context.Value(requestKey{}))
+	}
+}
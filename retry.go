@@ -0,0 +1,135 @@
+package gormqs
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type retryConfig struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	retryable   func(error) bool
+	onRetry     func(attempt int, err error, delay time.Duration)
+	txOpts      []TxOption
+}
+
+type RetryOption func(*retryConfig)
+
+// WithMaxAttempts attempts including the first one, default 3
+func WithMaxAttempts(n int) RetryOption {
+	return func(c *retryConfig) {
+		c.maxAttempts = n
+	}
+}
+
+// WithBackoff exponential backoff from base up to max with jitter, default 10ms and 1s
+func WithBackoff(base, max time.Duration) RetryOption {
+	return func(c *retryConfig) {
+		c.baseDelay, c.maxDelay = base, max
+	}
+}
+
+// WithRetryable classify retryable error instead of IsRetryable
+func WithRetryable(retryable func(error) bool) RetryOption {
+	return func(c *retryConfig) {
+		c.retryable = retryable
+	}
+}
+
+// OnRetry called before waiting for the next attempt, attempt is the failed one starting from 1
+func OnRetry(hook func(attempt int, err error, delay time.Duration)) RetryOption {
+	return func(c *retryConfig) {
+		c.onRetry = hook
+	}
+}
+
+// WithTxOptions options of every transaction attempt
+func WithTxOptions(opts ...TxOption) RetryOption {
+	return func(c *retryConfig) {
+		c.txOpts = opts
+	}
+}
+
+/*
+RetryTransaction run fn in a transaction like Transaction, the whole transaction is retried
+on serialization failures, deadlocks and lock timeouts, see IsRetryable.
+fn must be safe to run again, side effects outside of the database belong to OnCommit.
+
+	err := gormqs.RetryTransaction(ctx, db, func(ctx context.Context) error {
+		_, err := userQueries.BlockBalance(ctx, userID, amount)
+		return err
+	},
+		gormqs.WithMaxAttempts(5),
+		gormqs.WithTxOptions(gormqs.WithIsolation(sql.LevelSerializable)),
+		gormqs.OnRetry(func(attempt int, err error, delay time.Duration) {
+			log.Printf("attempt %d failed, retry in %s: %v", attempt, delay, err)
+		}),
+	)
+
+inside another transaction fn runs once in a savepoint, a failed transaction can not be retried
+partially, the error is returned so the outermost runner retries.
+no retry when the next attempt would start after the deadline of ctx
+*/
+func RetryTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error, opts ...RetryOption) error {
+	cfg := retryConfig{
+		maxAttempts: 3,
+		baseDelay:   10 * time.Millisecond,
+		maxDelay:    time.Second,
+		retryable:   IsRetryable,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if inTransaction(ContextDB(ctx, newTxConfig(cfg.txOpts).name, db)) {
+		return Transaction(ctx, db, fn, cfg.txOpts...)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := Transaction(ctx, db, fn, cfg.txOpts...)
+		if err == nil || attempt >= cfg.maxAttempts || !cfg.retryable(err) {
+			return err
+		}
+
+		delay := cfg.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		if cfg.onRetry != nil {
+			cfg.onRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, context.Cause(ctx))
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff exponential delay of attempt, half fixed and half random
+func (c *retryConfig) backoff(attempt int) time.Duration {
+	delay := c.baseDelay
+	for i := 1; i < attempt && delay < c.maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, c.maxDelay)
+
+	if half := delay / 2; half > 0 {
+		return half + rand.N(half)
+	}
+	return delay
+}
+
+// inTransaction report whether db is bound to a transaction
+func inTransaction(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
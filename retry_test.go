@@ -0,0 +1,129 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"postgres serialization", &fakePgError{Code: "40001"}, true},
+		{"postgres deadlock", &fakePgError{Code: "40P01"}, true},
+		{"postgres duplicate", &fakePgError{Code: "23505"}, false},
+		{"mysql deadlock", &fakeMySQLError{Number: 1213}, true},
+		{"mysql lock wait timeout", &fakeMySQLError{Number: 1205}, true},
+		{"sqlite busy", fakeSqliteError{Code: 5, ExtendedCode: 5}, true},
+		{"sqlite busy snapshot", fakeSqliteError{Code: 5, ExtendedCode: 517}, true},
+		{"sqlite locked", fakeSqliteError{Code: 6, ExtendedCode: 6}, true},
+		{"sqlite unique", fakeSqliteError{Code: 19, ExtendedCode: 2067}, false},
+		{"wrapped", fmt.Errorf("update: %w", &fakePgError{Code: "40001"}), true},
+		{"joined", errors.Join(errors.New("other"), &fakeMySQLError{Number: 1213}), true},
+		{"plain", errors.New("boom"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gormqs.IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRetryTransaction(t *testing.T) {
+	events := new([]string)
+	db, err := gorm.Open(savepointDialector{events: events}, &gorm.Config{ConnPool: txRecorder{events: events}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		calls    int
+		attempts []int
+	)
+	err = gormqs.RetryTransaction(context.Background(), db, func(ctx context.Context) error {
+		if calls++; calls < 3 {
+			return &fakePgError{Code: "40001"}
+		}
+		return nil
+	},
+		gormqs.WithBackoff(time.Millisecond, 2*time.Millisecond),
+		gormqs.OnRetry(func(attempt int, err error, delay time.Duration) {
+			attempts = append(attempts, attempt)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(attempts, []int{1, 2}) {
+		t.Errorf("expected retry of attempts [1 2], got %v", attempts)
+	}
+
+	begin := "begin Default readonly=false"
+	expected := []string{begin, "rollback", begin, "rollback", begin, "commit"}
+	if !reflect.DeepEqual(*events, expected) {
+		t.Errorf("expected %v, got %v", expected, *events)
+	}
+
+	t.Run("max attempts", func(t *testing.T) {
+		calls = 0
+		err := gormqs.RetryTransaction(context.Background(), db, func(ctx context.Context) error {
+			calls++
+			return &fakeMySQLError{Number: 1213}
+		}, gormqs.WithMaxAttempts(2), gormqs.WithBackoff(time.Millisecond, time.Millisecond))
+		if !gormqs.IsRetryable(err) || calls != 2 {
+			t.Errorf("expected deadlock after 2 calls, got %v after %d", err, calls)
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		calls = 0
+		boom := errors.New("boom")
+		err := gormqs.RetryTransaction(context.Background(), db, func(ctx context.Context) error {
+			calls++
+			return boom
+		})
+		if !errors.Is(err, boom) || calls != 1 {
+			t.Errorf("expected boom after 1 call, got %v after %d", err, calls)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		calls = 0
+		err := gormqs.RetryTransaction(ctx, db, func(ctx context.Context) error {
+			calls++
+			return &fakePgError{Code: "40P01"}
+		}, gormqs.WithBackoff(time.Second, time.Second))
+		if !gormqs.IsRetryable(err) || calls != 1 {
+			t.Errorf("expected no retry after deadline, got %v after %d", err, calls)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		calls = 0
+		_ = gormqs.Transaction(context.Background(), db, func(ctx context.Context) error {
+			return gormqs.RetryTransaction(ctx, db, func(ctx context.Context) error {
+				calls++
+				return &fakePgError{Code: "40001"}
+			})
+		})
+		if calls != 1 {
+			t.Errorf("expected nested runner to run once, got %d", calls)
+		}
+	})
+}
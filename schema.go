@@ -0,0 +1,129 @@
+package gormqs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var ErrUnknownField = errors.New("gormqs: unknown field")
+
+/*
+ModelSchema runtime schema of model M parsed by gorm, an alternative to gormqs-gen
+
+	var (
+		USER        = gormqs.MustSchemaOf[models.User]()
+		UserBalance = gormqs.MustTypedColumn[float64](USER, "Balance")
+	)
+
+	userQueries.GetMany(ctx,
+		USER.MustSelect("ID", "Balance"),
+		UserBalance.Gt(100),
+		gormqs.OrderBy(USER.MustColumn("CreatedAt").Desc()),
+	)
+
+unknown field panic when the package is initialized instead of at query time
+*/
+type ModelSchema[M Model] struct {
+	schema *schema.Schema
+}
+
+// SchemaOf parse model M with gorm default naming strategy unless namer is given
+func SchemaOf[M Model](namer ...schema.Namer) (*ModelSchema[M], error) {
+	var (
+		model  M
+		naming schema.Namer = schema.NamingStrategy{}
+	)
+	if len(namer) > 0 {
+		naming = namer[0]
+	}
+
+	// parsed once per call, a shared cache would keep the names of the first namer
+	sch, err := schema.Parse(&model, &sync.Map{}, naming)
+	if err != nil {
+		return nil, err
+	}
+	return &ModelSchema[M]{schema: sch}, nil
+}
+
+// MustSchemaOf same as SchemaOf, panic on error
+func MustSchemaOf[M Model](namer ...schema.Namer) *ModelSchema[M] {
+	return must(SchemaOf[M](namer...))
+}
+
+// Table name of model
+func (s *ModelSchema[M]) Table() string {
+	return s.schema.Table
+}
+
+// Column handle of Go field name
+func (s *ModelSchema[M]) Column(fieldName string) (Column[M, any], error) {
+	field, err := s.field(fieldName)
+	if err != nil {
+		return Column[M, any]{}, err
+	}
+	return NewColumn[M, any](field.DBName), nil
+}
+
+// MustColumn same as Column, panic on unknown field
+func (s *ModelSchema[M]) MustColumn(fieldName string) Column[M, any] {
+	return must(s.Column(fieldName))
+}
+
+// Select columns of Go field names
+func (s *ModelSchema[M]) Select(fieldNames ...string) (Option, error) {
+	columns := make([]string, len(fieldNames))
+	for i, name := range fieldNames {
+		field, err := s.field(name)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = field.DBName
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	}, nil
+}
+
+// MustSelect same as Select, panic on unknown field
+func (s *ModelSchema[M]) MustSelect(fieldNames ...string) Option {
+	return must(s.Select(fieldNames...))
+}
+
+func (s *ModelSchema[M]) field(name string) (*schema.Field, error) {
+	field, ok := s.schema.FieldsByName[name]
+	if !ok || field.DBName == "" {
+		return nil, fmt.Errorf("%w: %s.%s", ErrUnknownField, s.schema.Name, name)
+	}
+	return field, nil
+}
+
+// TypedColumn column handle of Go field name, T must be the field type
+func TypedColumn[T any, M Model](s *ModelSchema[M], fieldName string) (Column[M, T], error) {
+	field, err := s.field(fieldName)
+	if err != nil {
+		return Column[M, T]{}, err
+	}
+
+	if expected := reflect.TypeFor[T](); field.FieldType != expected {
+		return Column[M, T]{}, fmt.Errorf("gormqs: %s.%s is %s, not %s", s.schema.Name, fieldName, field.FieldType, expected)
+	}
+	return NewColumn[M, T](field.DBName), nil
+}
+
+// MustTypedColumn same as TypedColumn, panic on error
+func MustTypedColumn[T any, M Model](s *ModelSchema[M], fieldName string) Column[M, T] {
+	return must(TypedColumn[T](s, fieldName))
+}
+
+func must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
@@ -0,0 +1,52 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm/schema"
+)
+
+func TestSchemaOf(t *testing.T) {
+	user := gormqs.MustSchemaOf[testUser]()
+	if table := user.Table(); table != "users" {
+		t.Errorf("got table %s, want users", table)
+	}
+
+	if _, err := user.Column("Unknown"); !errors.Is(err, gormqs.ErrUnknownField) {
+		t.Errorf("got %v, want ErrUnknownField", err)
+	}
+
+	if _, err := gormqs.TypedColumn[string](user, "Balance"); err == nil {
+		t.Error("mismatched field type must fail")
+	}
+
+	qs, sqls := newTestQueries[testUser](t)
+	_, err := qs.GetMany(context.Background(),
+		user.MustSelect("ID", "Username"),
+		gormqs.MustTypedColumn[float64](user, "Balance").Gte(10),
+		gormqs.OrderBy(user.MustColumn("Username").Desc()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "SELECT `id`,`username` FROM `users` WHERE `users`.`balance` >= ? ORDER BY `users`.`username` DESC"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
+
+func TestSchemaOfNamer(t *testing.T) {
+	lower := gormqs.MustSchemaOf[testUser]()
+	exact := gormqs.MustSchemaOf[testUser](schema.NamingStrategy{NoLowerCase: true})
+
+	if name := lower.MustColumn("Username").Name(); name != "username" {
+		t.Errorf("got %s, want username", name)
+	}
+	if name := exact.MustColumn("Username").Name(); name != "Username" {
+		t.Errorf("got %s, want Username", name)
+	}
+}
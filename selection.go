@@ -0,0 +1,68 @@
+package gormqs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidSelection = errors.New("gormqs: invalid selection")
+
+// Selection flags of what a list query return
+type Selection uint8
+
+const (
+	// SelectList query rows
+	SelectList Selection = 1 << iota
+	// SelectCount query total rows with COUNT
+	SelectCount
+	// SelectHasNext fetch limit+1 rows to report whether more rows exist, without COUNT
+	SelectHasNext
+)
+
+var selectionNames = map[string]Selection{
+	"list":    SelectList,
+	"count":   SelectCount,
+	"hasnext": SelectHasNext,
+}
+
+/*
+ParseSelection parse comma separated selection, case insensitive, unknown value is an error
+
+	sel, err := ParseSelection("list,hasNext") // SelectList | SelectHasNext
+	sel, err := ParseSelection("lsit")         // ErrInvalidSelection
+*/
+func ParseSelection(raw string) (Selection, error) {
+	var sel Selection
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		flag, ok := selectionNames[strings.ToLower(part)]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown value %q", ErrInvalidSelection, part)
+		}
+		sel |= flag
+	}
+	return sel, nil
+}
+
+// Has report whether every flag of other is selected
+func (s Selection) Has(other Selection) bool {
+	return other != 0 && s&other == other
+}
+
+func (s Selection) String() string {
+	var names []string
+	for _, flag := range []struct {
+		sel  Selection
+		name string
+	}{{SelectList, "list"}, {SelectCount, "count"}, {SelectHasNext, "hasNext"}} {
+		if s.Has(flag.sel) {
+			names = append(names, flag.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
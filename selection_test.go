@@ -0,0 +1,67 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected gormqs.Selection
+		err      error
+	}{
+		{raw: "list,count", expected: gormqs.SelectList | gormqs.SelectCount},
+		{raw: " List , hasNext", expected: gormqs.SelectList | gormqs.SelectHasNext},
+		{raw: "", expected: 0},
+		{raw: "lsit", err: gormqs.ErrInvalidSelection},
+		{raw: "counter", err: gormqs.ErrInvalidSelection},
+	}
+
+	for _, tt := range tests {
+		sel, err := gormqs.ParseSelection(tt.raw)
+		if !errors.Is(err, tt.err) || sel != tt.expected {
+			t.Errorf("%q: got %v %v, want %v %v", tt.raw, sel, err, tt.expected, tt.err)
+		}
+	}
+}
+
+func TestListResulterHasNext(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qs := &testQueries[testUser]{db: db}
+	qs.Queries = gormqs.NewQueries[testUser](qs)
+
+	var collector gormqs.SQLCollector
+	ctx := gormqs.DryRun(context.Background(), &collector)
+
+	resulter := gormqs.NewListResulter[testUser](gormqs.SelectHasNext)
+	if err := qs.GetListTo(ctx, resulter, gormqs.LimitAndOffset(2, 4)); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := collector.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("got %v, want list query only", statements)
+	}
+	if vars := statements[0].Vars; !reflect.DeepEqual(vars, []any{3, 4}) {
+		t.Errorf("got limit and offset %v, want [3 4]", vars)
+	}
+
+	// rows fetched by limit+1
+	*resulter.List = []*testUser{{ID: 1}, {ID: 2}, {ID: 3}}
+	if err := resulter.QsAfterList(); err != nil {
+		t.Fatal(err)
+	}
+	if len(*resulter.List) != 2 || !*resulter.HasNext {
+		t.Errorf("got %d rows has next %v, want 2 rows and next", len(*resulter.List), *resulter.HasNext)
+	}
+}
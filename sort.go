@@ -0,0 +1,155 @@
+package gormqs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidSort = errors.New("gormqs: invalid sort")
+
+// Nulls placement of NULL values
+type Nulls uint8
+
+const (
+	NullsDefault Nulls = iota
+	NullsFirst
+	NullsLast
+)
+
+// Sort order by column
+type Sort struct {
+	Column string
+	Desc   bool
+	Nulls  Nulls
+}
+
+// Asc sort column ascending
+func Asc(column string) Sort {
+	return Sort{Column: column}
+}
+
+// Desc sort column descending
+func Desc(column string) Sort {
+	return Sort{Column: column, Desc: true}
+}
+
+// NullsFirst place NULL values before others
+func (s Sort) NullsFirst() Sort {
+	s.Nulls = NullsFirst
+	return s
+}
+
+// NullsLast place NULL values after others
+func (s Sort) NullsLast() Sort {
+	s.Nulls = NullsLast
+	return s
+}
+
+func (s Sort) reverse() Sort {
+	s.Desc = !s.Desc
+	switch s.Nulls {
+	case NullsFirst:
+		s.Nulls = NullsLast
+	case NullsLast:
+		s.Nulls = NullsFirst
+	}
+	return s
+}
+
+// orderByColumn bind column to current table unless it is qualified like "orders.created_at"
+func (s Sort) orderByColumn() clause.OrderByColumn {
+	column := clause.Column{Name: s.Column}
+	if !strings.Contains(s.Column, ".") {
+		column.Table = clause.CurrentTable
+	}
+	return clause.OrderByColumn{Column: column, Desc: s.Desc}
+}
+
+// orderByColumns use NULLS FIRST/LAST on postgres, other dialects sort by a null flag first
+func (s Sort) orderByColumns(db *gorm.DB) []clause.OrderByColumn {
+	if s.Nulls == NullsDefault {
+		return []clause.OrderByColumn{s.orderByColumn()}
+	}
+
+	column := clause.Column{Name: s.Column}
+	if !strings.Contains(s.Column, ".") {
+		column.Table = db.Statement.Table
+	}
+	quoted := db.Statement.Quote(column)
+
+	if db.Dialector.Name() == "postgres" {
+		sql := quoted + " ASC NULLS "
+		if s.Desc {
+			sql = quoted + " DESC NULLS "
+		}
+		if s.Nulls == NullsFirst {
+			sql += "FIRST"
+		} else {
+			sql += "LAST"
+		}
+		return []clause.OrderByColumn{{Column: clause.Column{Name: sql, Raw: true}}}
+	}
+
+	flag := "CASE WHEN " + quoted + " IS NULL THEN 1 ELSE 0 END"
+	if s.Nulls == NullsFirst {
+		flag = "CASE WHEN " + quoted + " IS NULL THEN 0 ELSE 1 END"
+	}
+	return []clause.OrderByColumn{
+		{Column: clause.Column{Name: flag, Raw: true}},
+		s.orderByColumn(),
+	}
+}
+
+/*
+ParseSort parse comma separated sort query, "-" prefix is descending, whitelist map public name to column
+
+	sorts, err := ParseSort("-created_at,username", map[string]string{
+		"created_at": "created_at",
+		"username":   "username",
+	})
+	// [Desc("created_at"), Asc("username")]
+
+	userQueries.GetMany(ctx, OrderBy(sorts...))
+*/
+func ParseSort(raw string, whitelist map[string]string) ([]Sort, error) {
+	var (
+		sorts []Sort
+		seen  = map[string]bool{}
+	)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		// at most one leading sign
+		desc, name := false, part
+		switch part[0] {
+		case '-':
+			desc, name = true, part[1:]
+		case '+':
+			name = part[1:]
+		}
+		if name == "" || strings.ContainsAny(name[:1], "+-") {
+			return nil, fmt.Errorf("%w: invalid field %q", ErrInvalidSort, part)
+		}
+
+		column, ok := whitelist[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicated field %q", ErrInvalidSort, name)
+		}
+		seen[name] = true
+
+		sorts = append(sorts, Sort{Column: column, Desc: desc})
+	}
+
+	return sorts, nil
+}
@@ -0,0 +1,95 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type postgresDialector struct {
+	tests.DummyDialector
+}
+
+func (postgresDialector) Name() string {
+	return "postgres"
+}
+
+func TestOrderBy(t *testing.T) {
+	sorts := []gormqs.Sort{gormqs.Desc("balance").NullsLast(), gormqs.Asc("id")}
+
+	qs, sqls := newTestQueries[testUser](t)
+	if _, err := qs.GetMany(context.Background(), gormqs.OrderBy(sorts...)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "SELECT * FROM `users` ORDER BY CASE WHEN `users`.`balance` IS NULL THEN 1 ELSE 0 END,`users`.`balance` DESC,`users`.`id`"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+
+	db, err := gorm.Open(postgresDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg := &testQueries[testUser]{db: db}
+	pg.Queries = gormqs.NewQueries[testUser](pg)
+
+	var collector gormqs.SQLCollector
+	if _, err := pg.GetMany(gormqs.DryRun(context.Background(), &collector), gormqs.OrderBy(sorts...)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "SELECT * FROM `users` ORDER BY `users`.`balance` DESC NULLS LAST,`users`.`id`"
+	if sql := collector.Statements()[0].SQL; sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
+
+func TestOrderByQualifiedColumn(t *testing.T) {
+	qs, sqls := newTestQueries[testUser](t)
+	if _, err := qs.GetMany(context.Background(), gormqs.OrderBy(gormqs.Asc("orders.created_at"), gormqs.Desc("balance"))); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "SELECT * FROM `users` ORDER BY `orders`.`created_at`,`users`.`balance` DESC"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+
+	// typed column keep its table too
+	column := gormqs.NewColumn[testUser, time.Time]("orders.created_at")
+	if _, err := qs.GetMany(context.Background(), gormqs.OrderBy(column.Desc())); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "SELECT * FROM `users` ORDER BY `orders`.`created_at` DESC"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	whitelist := map[string]string{"created_at": "created_at", "name": "username"}
+
+	sorts, err := gormqs.ParseSort("-created_at, +name", whitelist)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []gormqs.Sort{gormqs.Desc("created_at"), gormqs.Asc("username")}
+	if !reflect.DeepEqual(sorts, expected) {
+		t.Errorf("got %v, want %v", sorts, expected)
+	}
+
+	for _, raw := range []string{"password", "name,-name", "--name", "+-name", "-+-name", "-"} {
+		if _, err := gormqs.ParseSort(raw, whitelist); !errors.Is(err, gormqs.ErrInvalidSort) {
+			t.Errorf("%s: got %v, want ErrInvalidSort", raw, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package gormqs
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+type txConfig struct {
+	options *sql.TxOptions
+	name    string
+}
+
+// TxOption option of transaction
+type TxOption func(*txConfig)
+
+// WithIsolation isolation level of the outermost transaction
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) {
+		c.sqlOptions().Isolation = level
+	}
+}
+
+// ReadOnly read only outermost transaction
+func ReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.sqlOptions().ReadOnly = true
+	}
+}
+
+/*
+NamedTx bind the tx to ctx under name, queries of WithDBName(name) use it, see ContextWithDB
+
+	err := gormqs.Transaction(ctx, billingDB, func(ctx context.Context) error {
+		return invoiceQueries.CreateOne(ctx, invoice)
+	}, gormqs.NamedTx("billing"))
+*/
+func NamedTx(name string) TxOption {
+	return func(c *txConfig) {
+		c.name = name
+	}
+}
+
+func (c *txConfig) sqlOptions() *sql.TxOptions {
+	if c.options == nil {
+		c.options = &sql.TxOptions{}
+	}
+	return c.options
+}
+
+func newTxConfig(opts []TxOption) txConfig {
+	var cfg txConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+/*
+Transaction run fn in a transaction, ctx passed to fn carries the tx so queries use it.
+tx of ctx is reused when exists and fn runs in a savepoint instead, rollback to savepoint on error.
+isolation and read only only apply when a new transaction is started, nested calls keep options of the outer transaction
+
+	err := gormqs.Transaction(ctx, db, func(ctx context.Context) error {
+		if err := orderQueries.CreateOne(ctx, order); err != nil {
+			return err
+		}
+
+		// SAVEPOINT inside, same tx
+		_, err := userQueries.LockForUpdate(ctx, order.UserID, ...)
+		return err
+	}, gormqs.WithIsolation(sql.LevelSerializable))
+*/
+func Transaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error, opts ...TxOption) error {
+	cfg := newTxConfig(opts)
+
+	// clean statement, table or conditions of db must not leak into fn
+	base := ContextDB(ctx, cfg.name, db).Session(&gorm.Session{NewDB: true, Context: ctx})
+
+	var txOpts []*sql.TxOptions
+	if cfg.options != nil {
+		txOpts = append(txOpts, cfg.options)
+	}
+
+	// callbacks registered in a savepoint are rolled back with it
+	var sp savepoint
+	callbacks := poolCallbacks(base.Statement.ConnPool)
+	if callbacks != nil {
+		sp = callbacks.savepoint()
+	}
+
+	err := base.Transaction(func(tx *gorm.DB) error {
+		return fn(ReplaceNamedContext(cfg.name, tx))
+	}, txOpts...)
+	if err != nil && callbacks != nil {
+		callbacks.rollbackTo(sp)
+	}
+	return err
+}
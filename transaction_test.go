@@ -0,0 +1,106 @@
+package gormqs_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// txRecorder conn pool, tx and dialector recording transaction events
+type txRecorder struct {
+	gorm.ConnPool
+	events *[]string
+}
+
+func (r txRecorder) BeginTx(_ context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	if opts == nil {
+		opts = &sql.TxOptions{}
+	}
+	*r.events = append(*r.events, fmt.Sprintf("begin %s readonly=%t", opts.Isolation, opts.ReadOnly))
+	return &txRecorderTx{events: r.events}, nil
+}
+
+type txRecorderTx struct {
+	gorm.ConnPool
+	events *[]string
+}
+
+func (r *txRecorderTx) Commit() error {
+	*r.events = append(*r.events, "commit")
+	return nil
+}
+
+func (r *txRecorderTx) Rollback() error {
+	*r.events = append(*r.events, "rollback")
+	return nil
+}
+
+type savepointDialector struct {
+	tests.DummyDialector
+	events *[]string
+}
+
+func (d savepointDialector) SavePoint(*gorm.DB, string) error {
+	*d.events = append(*d.events, "savepoint")
+	return nil
+}
+
+func (d savepointDialector) RollbackTo(*gorm.DB, string) error {
+	*d.events = append(*d.events, "rollback to")
+	return nil
+}
+
+func TestTransaction(t *testing.T) {
+	events := new([]string)
+	db, err := gorm.Open(savepointDialector{events: events}, &gorm.Config{ConnPool: txRecorder{events: events}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errNested := errors.New("nested")
+	err = gormqs.Transaction(context.Background(), db, func(ctx context.Context) error {
+		tx := gormqs.ContextValue[*gorm.DB](ctx, nil)
+		if _, ok := tx.Statement.ConnPool.(gorm.TxCommitter); !ok {
+			t.Fatal("ctx does not carry the tx")
+		}
+
+		err := gormqs.Transaction(ctx, db, func(ctx context.Context) error {
+			if nested := gormqs.ContextValue[*gorm.DB](ctx, nil); nested.Statement.ConnPool != tx.Statement.ConnPool {
+				t.Error("nested transaction does not reuse the tx")
+			}
+			return errNested
+		}, gormqs.ReadOnly())
+		if !errors.Is(err, errNested) {
+			t.Errorf("expected nested error, got %v", err)
+		}
+		return nil
+	}, gormqs.WithIsolation(sql.LevelSerializable), gormqs.ReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"begin Serializable readonly=true", "savepoint", "rollback to", "commit"}
+	if !reflect.DeepEqual(*events, expected) {
+		t.Errorf("expected %v, got %v", expected, *events)
+	}
+
+	*events = nil
+	err = gormqs.Transaction(context.Background(), db, func(ctx context.Context) error {
+		return errNested
+	})
+	if !errors.Is(err, errNested) {
+		t.Errorf("expected fn error, got %v", err)
+	}
+
+	expected = []string{"begin Default readonly=false", "rollback"}
+	if !reflect.DeepEqual(*events, expected) {
+		t.Errorf("expected %v, got %v", expected, *events)
+	}
+}
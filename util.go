@@ -3,29 +3,30 @@ package gormqs
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
-type (
-	contextKey string
-)
+// contextKey value of type typ, name tell apart values of the same type e.g. connections of many databases
+type contextKey struct {
+	typ  reflect.Type
+	name string
+}
 
+// ContextWithValue store values keyed by their dynamic type, one value per type
 func ContextWithValue(ctx context.Context, values ...any) context.Context {
 	for _, val := range values {
-		key := contextKey(fmt.Sprintf("%T", val))
-		ctx = context.WithValue(ctx, key, val)
+		ctx = context.WithValue(ctx, contextKey{typ: reflect.TypeOf(val)}, val)
 	}
 	return ctx
 }
 
+// ContextValue value of type T stored by ContextWithValue, fallback when missing
 func ContextValue[T any](ctx context.Context, fallback T) T {
-	var (
-		zero T
-		key  = contextKey(fmt.Sprintf("%T", zero))
-	)
-	casted, ok := ctx.Value(key).(T)
+	casted, ok := ctx.Value(contextKey{typ: reflect.TypeFor[T]()}).(T)
 	if !ok {
 		return fallback
 	}
@@ -33,6 +34,31 @@ func ContextValue[T any](ctx context.Context, fallback T) T {
 	return casted
 }
 
+/*
+ContextWithDB store db under name, name "" is the db of ContextWithValue and WrapContext
+
+	ctx = gormqs.ContextWithDB(ctx, "billing", billingTx)
+	ctx = gormqs.ContextWithDB(ctx, "catalog", catalogTx)
+*/
+func ContextWithDB(ctx context.Context, name string, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, dbKey(name), db)
+}
+
+// ContextDB db stored under name, fallback when missing, dbs of other names are never used
+func ContextDB(ctx context.Context, name string, fallback *gorm.DB) *gorm.DB {
+	db, ok := ctx.Value(dbKey(name)).(*gorm.DB)
+	if !ok || db == nil {
+		return fallback
+	}
+	return db
+}
+
+var dbType = reflect.TypeFor[*gorm.DB]()
+
+func dbKey(name string) contextKey {
+	return contextKey{typ: dbType, name: name}
+}
+
 // safeTextForSql sanitizes the input text by replacing unsafe characters for SQL queries.
 func SafeTextForSql(text string) string {
 	// Replace wildcard asterisk (*) with SQL's LIKE wildcard (%)
@@ -68,3 +94,12 @@ func WithTable(col string, db *gorm.DB) string {
 
 	return fmt.Sprintf("`%s`.`%s`", db.Statement.Table, col)
 }
+
+// parseSchema parse gorm schema of model with naming strategy and schema cache of db
+func parseSchema(db *gorm.DB, model any) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
@@ -0,0 +1,97 @@
+package gormqs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrStaleRecord = errors.New("gormqs: stale record, version has changed")
+
+/*
+Versioned opt model into optimistic locking on Queries.Update
+
+	type User struct {
+		ID      uint
+		Balance float64
+		Version int64
+	}
+
+	func (User) VersionColumn() string {
+		return "version"
+	}
+
+	// SQL: UPDATE "users" SET "balance"=100,"version"=4 WHERE "users"."version" = 3 AND "id" = 1
+	_, err := qs.Update(ctx, &user, Select("balance"))
+	if errors.Is(err, ErrStaleRecord) {
+		// reload and retry
+	}
+
+version column must be an integer field, it is incremented on record after update
+*/
+type Versioned interface {
+	VersionColumn() string
+}
+
+// withVersion add version condition and increment version of record, restore revert the version and is nil when record is not Versioned
+func withVersion(db *gorm.DB, record any) (tx *gorm.DB, restore func(), err error) {
+	versioned, ok := record.(Versioned)
+	if !ok {
+		return db, nil, nil
+	}
+
+	// version condition must not turn an unscoped update into a scoped one
+	if !db.AllowGlobalUpdate && !isScoped(db) {
+		return db, nil, fmt.Errorf("%w: %w", ErrMissingWhere, gorm.ErrMissingWhereClause)
+	}
+
+	sch, err := parseSchema(db, record)
+	if err != nil {
+		return db, nil, err
+	}
+
+	field := sch.LookUpField(versioned.VersionColumn())
+	if field == nil {
+		return db, nil, fmt.Errorf("gormqs: version column %q not found in %s", versioned.VersionColumn(), sch.Name)
+	}
+
+	var (
+		ctx        = db.Statement.Context
+		rv         = reflect.ValueOf(record).Elem()
+		current, _ = field.ValueOf(ctx, rv)
+		next       any
+	)
+
+	switch v := reflect.ValueOf(current); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		next = v.Int() + 1
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		next = v.Uint() + 1
+	default:
+		return db, nil, fmt.Errorf("gormqs: version column %q must be integer, got %T", field.DBName, current)
+	}
+
+	if err := field.Set(ctx, rv, next); err != nil {
+		return db, nil, err
+	}
+
+	tx = db.Where(clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+		Value:  current,
+	})
+
+	// selected columns must include version
+	if len(tx.Statement.Selects) > 0 && !slices.Contains(tx.Statement.Selects, "*") {
+		tx.Statement.Selects = append(slices.Clip(tx.Statement.Selects), field.DBName)
+	}
+
+	restore = func() {
+		_ = field.Set(ctx, rv, current)
+	}
+
+	return tx, restore, nil
+}
@@ -0,0 +1,44 @@
+package gormqs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/foxie-io/gormqs"
+)
+
+type testVersionedUser struct {
+	ID      uint
+	Balance float64
+	Version int64
+}
+
+func (testVersionedUser) TableName() string {
+	return "users"
+}
+
+func (testVersionedUser) VersionColumn() string {
+	return "version"
+}
+
+func TestUpdateVersioned(t *testing.T) {
+	qs, sqls := newTestQueries[testVersionedUser](t)
+
+	user := testVersionedUser{ID: 1, Balance: 100, Version: 3}
+	_, err := qs.Update(context.Background(), &user, gormqs.Select("balance"))
+
+	// dry run never affect rows
+	if !errors.Is(err, gormqs.ErrStaleRecord) {
+		t.Fatalf("got %v, want ErrStaleRecord", err)
+	}
+
+	expected := "UPDATE `users` SET `balance`=?,`version`=? WHERE `users`.`version` = ? AND `id` = ?"
+	if sql := lastSQL(t, sqls); sql != expected {
+		t.Errorf("got %s, want %s", sql, expected)
+	}
+
+	if user.Version != 3 {
+		t.Errorf("version must be restored on stale record, got %d", user.Version)
+	}
+}